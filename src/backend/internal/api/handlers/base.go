@@ -28,25 +28,39 @@ func (handler *Handler) HandleIPC(request models.Request, encoder *json.Encoder)
 	case "ping", "quit", "open-ui", "restart-ui":
 		return handler.HandleSystem(request, encoder)
 
-	case "get-config", "write-config", "open-config-editor", "toggle-autostart":
+	case "get-config", "write-config", "open-config-editor", "toggle-autostart",
+		"get-steam-libraries", "select-steam-library":
 		return handler.HandleConfig(request)
 
 	case "get-screens":
 		return handler.HandleDisplay(request)
 
-	case "apply-wallpapers", "load-wallpapers", "get-wallpaper-project-data",
+	case "apply-wallpapers", "load-wallpapers", "refresh-wallpaper-catalog", "get-wallpaper-project-data",
+		"get-wallpaper-property-schema",
 		"get-wallpaper-base-path", "get-assets-base-path", "kill-all-wallpapers", "kill-wallpaper",
 		"start-preview", "stop-preview", "is-preview-running":
 		return handler.HandleWallpaper(request)
 
 	case "get-playlists", "create-playlist", "rename-playlist", "delete-playlist",
 		"update-playlist-wallpapers", "start-playlist", "stop-playlist",
-		"update-playlist-interval", "get-playlist-status":
+		"update-playlist-interval", "get-playlist-status",
+		"playlist-next", "playlist-previous", "playlist-queue",
+		"get-playlist-store", "import-we-playlists", "export-we-playlists",
+		"export-playlist", "import-playlist", "validate-playlists", "repair-playlist",
+		"get-smart-playlists", "save-smart-playlist", "delete-smart-playlist", "evaluate-smart-query":
 		return handler.HandlePlaylist(request)
 
-	case "get-installed-filters", "save-installed-filters", "get-workshop-filters", "save-workshop-filters":
+	case "get-installed-filters", "save-installed-filters", "get-workshop-filters", "save-workshop-filters",
+		"query-wallpapers":
 		return handler.HandleFilter(request)
 
+	case "get-ratings", "set-rating", "set-favorite":
+		return handler.HandleRating(request)
+
+	case "get-presets", "save-preset", "rename-preset", "delete-preset", "apply-preset",
+		"import-preset-file", "import-we-properties":
+		return handler.HandlePreset(request)
+
 	default:
 		return models.Response{
 			ID:    request.ID,
@@ -5,6 +5,8 @@ import (
 
 	"linux-wallpaperengine-gui/src/backend/internal/api/models"
 	"linux-wallpaperengine-gui/src/backend/internal/config"
+	"linux-wallpaperengine-gui/src/backend/internal/core/wallpaper"
+	"linux-wallpaperengine-gui/src/backend/internal/logger"
 	"linux-wallpaperengine-gui/src/backend/internal/ui/tray"
 )
 
@@ -25,7 +27,10 @@ func (handler *Handler) HandleConfig(request models.Request) models.Response {
 		if err := json.Unmarshal(request.Params, &appConfig); err != nil {
 			response.Error = err.Error()
 		} else {
-			if err := config.WriteConfig(appConfig); err != nil {
+			current, _ := config.ReadConfig()
+			if err := wallpaper.ValidateConfigProperties(current, appConfig); err != nil {
+				response.Error = err.Error()
+			} else if err := config.WriteConfig(appConfig); err != nil {
 				response.Error = err.Error()
 			} else {
 				tray.UpdateTitle(appConfig.HideTrayLabel)
@@ -43,6 +48,39 @@ func (handler *Handler) HandleConfig(request models.Request) models.Response {
 				response.Result = map[string]bool{"success": true}
 			}
 		}
+	case "get-steam-libraries":
+		libraries, err := config.GetSteamLibraries()
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			wallpaperEnginePath, workshopPath, err := config.ResolvePaths()
+			if err != nil {
+				logger.Printf("Failed to resolve paths in get-steam-libraries: %v", err)
+			}
+			response.Result = map[string]interface{}{
+				"success":             true,
+				"libraries":           libraries,
+				"workshopPath":        workshopPath,
+				"wallpaperEnginePath": wallpaperEnginePath,
+			}
+		}
+	case "select-steam-library":
+		var parameters struct {
+			Path string `json:"path"`
+		}
+		if err := json.Unmarshal(request.Params, &parameters); err != nil {
+			response.Error = err.Error()
+		} else {
+			if err := config.SelectSteamLibrary(parameters.Path); err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]interface{}{
+					"success":             true,
+					"workshopPath":        config.GetWorkshopPath(),
+					"wallpaperEnginePath": config.GetWallpaperEnginePath(),
+				}
+			}
+		}
 
 	case "open-config-editor":
 		if err := config.OpenConfigEditor(); err != nil {
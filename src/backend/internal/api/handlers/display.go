@@ -11,11 +11,15 @@ func (handler *Handler) HandleDisplay(request models.Request) models.Response {
 
 	switch request.Method {
 	case "get-screens":
-		screens, err := display.GetScreens()
+		screenInfo, err := display.GetScreenInfo()
 		if err != nil {
 			response.Error = err.Error()
 		} else {
-			response.Result = map[string]interface{}{"success": true, "screens": screens}
+			screens := []string{}
+			for _, info := range screenInfo {
+				screens = append(screens, info.Name)
+			}
+			response.Result = map[string]interface{}{"success": true, "screens": screens, "screenInfo": screenInfo}
 		}
 	}
 
@@ -49,6 +49,27 @@ func (handler *Handler) HandleFilter(request models.Request) models.Response {
 				response.Result = map[string]bool{"success": true}
 			}
 		}
+	case "query-wallpapers":
+		var query filter.Query
+		if len(request.Params) > 0 {
+			if err := json.Unmarshal(request.Params, &query); err != nil {
+				response.Error = err.Error()
+				break
+			}
+		}
+		result, err := filter.QueryWallpapers(query)
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Result = map[string]interface{}{
+				"success":    true,
+				"wallpapers": result.Wallpapers,
+				"total":      result.Total,
+				"installed":  result.Installed,
+				"offset":     result.Offset,
+				"limit":      result.Limit,
+			}
+		}
 	}
 
 	return response
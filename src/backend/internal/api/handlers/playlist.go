@@ -2,11 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 
 	"linux-wallpaperengine-gui/src/backend/internal/api/models"
-	"linux-wallpaperengine-gui/src/backend/internal/config"
 	"linux-wallpaperengine-gui/src/backend/internal/core/playlist"
+	"linux-wallpaperengine-gui/src/backend/internal/core/wallpaper"
+	"linux-wallpaperengine-gui/src/backend/internal/logger"
 )
 
 func (handler *Handler) HandlePlaylist(request models.Request) models.Response {
@@ -19,7 +19,11 @@ func (handler *Handler) HandlePlaylist(request models.Request) models.Response {
 		if err != nil {
 			response.Error = err.Error()
 		} else {
-			response.Result = map[string]interface{}{"success": true, "playlists": playlists}
+			response.Result = map[string]interface{}{
+				"success":    true,
+				"playlists":  playlists,
+				"unresolved": playlist.UnresolvedItems(playlists),
+			}
 		}
 	case "create-playlist":
 		var parameters struct {
@@ -83,35 +87,28 @@ func (handler *Handler) HandlePlaylist(request models.Request) models.Response {
 		}
 		if err := json.Unmarshal(request.Params, &parameters); err != nil {
 			response.Error = err.Error()
+		} else if parameters.PlaylistName == "" {
+			response.Error = "playlistName is required"
 		} else {
-			screenName := parameters.ScreenName
-			if screenName == "" {
-				screenName = "Global"
-			}
-			appConfig, err := config.ReadConfig()
-			if err != nil {
-				response.Error = fmt.Sprintf("failed to read config: %v", err)
+			if err := handler.playlistService.AssignPlaylist(parameters.ScreenName, parameters.PlaylistName, parameters.IntervalMinutes); err != nil {
+				response.Error = err.Error()
+			} else if err := handler.playlistService.StartPlaylistCycle(parameters.ScreenName); err != nil {
+				response.Error = err.Error()
 			} else {
-				appConfig.Playlist = parameters.PlaylistName
-				appConfig.PlaylistInterval = parameters.IntervalMinutes
-				if err := config.WriteConfig(appConfig); err != nil {
-					response.Error = fmt.Sprintf("failed to write config: %v", err)
-				} else if err := handler.playlistService.StartPlaylistCycle(screenName); err != nil {
-					response.Error = err.Error()
-				} else {
-					response.Result = map[string]bool{"success": true}
-				}
+				response.Result = map[string]bool{"success": true}
 			}
 		}
 	case "stop-playlist":
 		var parameters struct {
 			ScreenName string `json:"screenName"`
 		}
-		screenName := "Global"
-		if err := json.Unmarshal(request.Params, &parameters); err == nil && parameters.ScreenName != "" {
-			screenName = parameters.ScreenName
+		if len(request.Params) > 0 {
+			_ = json.Unmarshal(request.Params, &parameters)
+		}
+		handler.playlistService.StopPlaylistCycle(parameters.ScreenName)
+		if err := handler.playlistService.AssignPlaylist(parameters.ScreenName, "", 0); err != nil {
+			logger.Printf("Failed to clear playlist assignment for screen '%s': %v", parameters.ScreenName, err)
 		}
-		handler.playlistService.StopPlaylistCycle(screenName)
 		response.Result = map[string]bool{"success": true}
 	case "update-playlist-interval":
 		var parameters struct {
@@ -122,24 +119,194 @@ func (handler *Handler) HandlePlaylist(request models.Request) models.Response {
 		if err := json.Unmarshal(request.Params, &parameters); err != nil {
 			response.Error = err.Error()
 		} else {
-			screenName := parameters.ScreenName
-			if screenName == "" {
-				screenName = "Global"
-			}
 			if err := playlist.UpdatePlaylistIntervalConfig(parameters.PlaylistName, parameters.IntervalMinutes); err != nil {
 				response.Error = err.Error()
+			} else if err := handler.playlistService.AssignPlaylist(parameters.ScreenName, parameters.PlaylistName, parameters.IntervalMinutes); err != nil {
+				response.Error = err.Error()
 			} else {
-				if appConfig, err := config.ReadConfig(); err == nil {
-					appConfig.PlaylistInterval = parameters.IntervalMinutes
-					_ = config.WriteConfig(appConfig)
-				}
-				_ = handler.playlistService.UpdatePlaylistInterval(screenName, parameters.IntervalMinutes)
+				_ = handler.playlistService.UpdatePlaylistInterval(parameters.ScreenName, parameters.IntervalMinutes)
 				response.Result = map[string]bool{"success": true}
 			}
 		}
 	case "get-playlist-status":
-		status := handler.playlistService.GetPlaylistStatus()
+		var parameters struct {
+			ScreenName string `json:"screenName"`
+		}
+		if len(request.Params) > 0 {
+			_ = json.Unmarshal(request.Params, &parameters)
+		}
+		status := handler.playlistService.GetPlaylistStatus(parameters.ScreenName)
 		response.Result = map[string]interface{}{"success": true, "status": status}
+	case "playlist-next":
+		var parameters struct {
+			ScreenName string `json:"screenName"`
+		}
+		if len(request.Params) > 0 {
+			_ = json.Unmarshal(request.Params, &parameters)
+		}
+		if err := handler.playlistService.NextWallpaper(parameters.ScreenName); err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Result = map[string]bool{"success": true}
+		}
+	case "playlist-previous":
+		var parameters struct {
+			ScreenName string `json:"screenName"`
+		}
+		if len(request.Params) > 0 {
+			_ = json.Unmarshal(request.Params, &parameters)
+		}
+		if err := handler.playlistService.PreviousWallpaper(parameters.ScreenName); err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Result = map[string]bool{"success": true}
+		}
+	case "playlist-queue":
+		var parameters struct {
+			ScreenName  string `json:"screenName"`
+			WallpaperID string `json:"wallpaperId"`
+			Clear       bool   `json:"clear"`
+		}
+		if len(request.Params) > 0 {
+			_ = json.Unmarshal(request.Params, &parameters)
+		}
+		if parameters.Clear {
+			if err := handler.playlistService.ClearQueue(parameters.ScreenName); err != nil {
+				response.Error = err.Error()
+				break
+			}
+		}
+		queue, err := handler.playlistService.QueueWallpaper(parameters.ScreenName, parameters.WallpaperID)
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Result = map[string]interface{}{"success": true, "queue": queue}
+		}
+	case "get-playlist-store":
+		store, err := playlist.ActiveStore()
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			_, weConfigErr := wallpaper.GetWEConfigPath()
+			response.Result = map[string]interface{}{
+				"success":                  true,
+				"store":                    store.Name(),
+				"wallpaperEngineAvailable": weConfigErr == nil,
+			}
+		}
+	case "import-we-playlists", "export-we-playlists":
+		var parameters struct {
+			Names     []string `json:"names"`
+			Overwrite bool     `json:"overwrite"`
+		}
+		if len(request.Params) > 0 {
+			_ = json.Unmarshal(request.Params, &parameters)
+		}
+		source, destination := playlist.WallpaperEngineStore(), playlist.NativeStore()
+		if request.Method == "export-we-playlists" {
+			source, destination = destination, source
+		}
+		copied, err := playlist.CopyPlaylists(source, destination, parameters.Names, parameters.Overwrite)
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Result = map[string]interface{}{"success": true, "playlists": copied}
+		}
+	case "export-playlist":
+		var parameters struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(request.Params, &parameters); err != nil {
+			response.Error = err.Error()
+		} else {
+			manifest, skipped, err := playlist.ExportPlaylist(parameters.Name)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]interface{}{"success": true, "manifest": manifest, "skipped": skipped}
+			}
+		}
+	case "import-playlist":
+		var parameters struct {
+			Manifest  playlist.PlaylistManifest `json:"manifest"`
+			Name      string                    `json:"name"`
+			Overwrite bool                      `json:"overwrite"`
+		}
+		if err := json.Unmarshal(request.Params, &parameters); err != nil {
+			response.Error = err.Error()
+		} else {
+			name, missing, err := playlist.ImportPlaylist(parameters.Manifest, parameters.Name, parameters.Overwrite)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]interface{}{"success": true, "name": name, "missing": missing}
+			}
+		}
+	case "validate-playlists":
+		reports, err := playlist.ValidatePlaylists()
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Result = map[string]interface{}{"success": true, "playlists": reports}
+		}
+	case "repair-playlist":
+		var parameters struct {
+			Name  string            `json:"name"`
+			Prune bool              `json:"prune"`
+			Remap map[string]string `json:"remap"`
+		}
+		if err := json.Unmarshal(request.Params, &parameters); err != nil {
+			response.Error = err.Error()
+		} else {
+			result, err := playlist.RepairPlaylist(parameters.Name, parameters.Prune, parameters.Remap)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]interface{}{"success": true, "result": result}
+			}
+		}
+	case "get-smart-playlists":
+		smartPlaylists, err := playlist.GetSmartPlaylists()
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Result = map[string]interface{}{"success": true, "playlists": smartPlaylists}
+		}
+	case "save-smart-playlist":
+		var smartPlaylist playlist.SmartPlaylist
+		if err := json.Unmarshal(request.Params, &smartPlaylist); err != nil {
+			response.Error = err.Error()
+		} else {
+			if err := playlist.SaveSmartPlaylist(smartPlaylist); err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]bool{"success": true}
+			}
+		}
+	case "delete-smart-playlist":
+		var parameters struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(request.Params, &parameters); err != nil {
+			response.Error = err.Error()
+		} else {
+			if err := playlist.DeleteSmartPlaylist(parameters.Name); err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]bool{"success": true}
+			}
+		}
+	case "evaluate-smart-query":
+		var query playlist.SmartQuery
+		if len(request.Params) > 0 {
+			_ = json.Unmarshal(request.Params, &query)
+		}
+		ids, err := playlist.EvaluateSmartQuery(query)
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Result = map[string]interface{}{"success": true, "wallpapers": ids}
+		}
 	}
 
 	return response
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"linux-wallpaperengine-gui/src/backend/internal/api/models"
+	"linux-wallpaperengine-gui/src/backend/internal/core/preset"
+)
+
+func (handler *Handler) HandlePreset(request models.Request) models.Response {
+	var response models.Response
+	response.ID = request.ID
+
+	switch request.Method {
+	case "get-presets":
+		var parameters struct {
+			WallpaperID string `json:"wallpaperId"`
+		}
+		if len(request.Params) > 0 {
+			_ = json.Unmarshal(request.Params, &parameters)
+		}
+
+		if parameters.WallpaperID != "" {
+			presets, err := preset.GetPresets(parameters.WallpaperID)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]interface{}{"success": true, "presets": presets}
+			}
+		} else {
+			presets, err := preset.GetAllPresets()
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]interface{}{"success": true, "presets": presets}
+			}
+		}
+	case "save-preset":
+		var parameters struct {
+			WallpaperID string        `json:"wallpaperId"`
+			Preset      preset.Preset `json:"preset"`
+			Overwrite   bool          `json:"overwrite"`
+		}
+		if err := json.Unmarshal(request.Params, &parameters); err != nil {
+			response.Error = err.Error()
+		} else {
+			saved, err := preset.SavePreset(parameters.WallpaperID, parameters.Preset, parameters.Overwrite)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]interface{}{"success": true, "preset": saved}
+			}
+		}
+	case "rename-preset":
+		var parameters struct {
+			WallpaperID string `json:"wallpaperId"`
+			OldName     string `json:"oldName"`
+			NewName     string `json:"newName"`
+		}
+		if err := json.Unmarshal(request.Params, &parameters); err != nil {
+			response.Error = err.Error()
+		} else if err := preset.RenamePreset(parameters.WallpaperID, parameters.OldName, parameters.NewName); err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Result = map[string]bool{"success": true}
+		}
+	case "delete-preset":
+		var parameters struct {
+			WallpaperID string `json:"wallpaperId"`
+			Name        string `json:"name"`
+		}
+		if err := json.Unmarshal(request.Params, &parameters); err != nil {
+			response.Error = err.Error()
+		} else if err := preset.DeletePreset(parameters.WallpaperID, parameters.Name); err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Result = map[string]bool{"success": true}
+		}
+	case "apply-preset":
+		var parameters struct {
+			WallpaperID string `json:"wallpaperId"`
+			Name        string `json:"name"`
+			ScreenName  string `json:"screenName"`
+		}
+		if err := json.Unmarshal(request.Params, &parameters); err != nil {
+			response.Error = err.Error()
+			break
+		}
+		if err := preset.ApplyPreset(parameters.WallpaperID, parameters.Name, parameters.ScreenName); err != nil {
+			response.Error = err.Error()
+			break
+		}
+		if err := handler.wallpaperService.ApplyWallpapers(); err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Result = map[string]bool{"success": true}
+		}
+	case "import-preset-file":
+		var parameters struct {
+			Path        string `json:"path"`
+			WallpaperID string `json:"wallpaperId"`
+			Name        string `json:"name"`
+		}
+		if err := json.Unmarshal(request.Params, &parameters); err != nil {
+			response.Error = err.Error()
+		} else {
+			imported, err := preset.ImportPresetFile(parameters.Path, parameters.WallpaperID, parameters.Name)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]interface{}{"success": true, "imported": imported}
+			}
+		}
+	case "import-we-properties":
+		var parameters struct {
+			WallpaperID string `json:"wallpaperId"`
+		}
+		if len(request.Params) > 0 {
+			_ = json.Unmarshal(request.Params, &parameters)
+		}
+
+		imported, err := preset.ImportWEProperties(parameters.WallpaperID)
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Result = map[string]interface{}{"success": true, "imported": imported}
+		}
+	}
+
+	return response
+}
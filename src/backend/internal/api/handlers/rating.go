@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"linux-wallpaperengine-gui/src/backend/internal/api/models"
+	"linux-wallpaperengine-gui/src/backend/internal/core/rating"
+)
+
+func (handler *Handler) HandleRating(request models.Request) models.Response {
+	var response models.Response
+	response.ID = request.ID
+
+	switch request.Method {
+	case "get-ratings":
+		var parameters struct {
+			WallpaperID string `json:"wallpaperId"`
+		}
+		if len(request.Params) > 0 {
+			_ = json.Unmarshal(request.Params, &parameters)
+		}
+
+		if parameters.WallpaperID != "" {
+			wallpaperRating, err := rating.GetRating(parameters.WallpaperID)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]interface{}{"success": true, "rating": wallpaperRating}
+			}
+		} else {
+			ratings, err := rating.GetRatings()
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]interface{}{"success": true, "ratings": ratings}
+			}
+		}
+	case "set-rating":
+		var parameters struct {
+			WallpaperID string `json:"wallpaperId"`
+			Rating      int    `json:"rating"`
+		}
+		if err := json.Unmarshal(request.Params, &parameters); err != nil {
+			response.Error = err.Error()
+		} else {
+			wallpaperRating, err := rating.SetRating(parameters.WallpaperID, parameters.Rating)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]interface{}{"success": true, "rating": wallpaperRating}
+			}
+		}
+	case "set-favorite":
+		var parameters struct {
+			WallpaperID string `json:"wallpaperId"`
+			Favorite    bool   `json:"favorite"`
+		}
+		if err := json.Unmarshal(request.Params, &parameters); err != nil {
+			response.Error = err.Error()
+		} else {
+			wallpaperRating, err := rating.SetFavorite(parameters.WallpaperID, parameters.Favorite)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]interface{}{"success": true, "rating": wallpaperRating}
+			}
+		}
+	}
+
+	return response
+}
@@ -36,6 +36,23 @@ func (handler *Handler) HandleWallpaper(request models.Request) models.Response
 				"wallpaperEnginePathValid": result["wallpaperEnginePathValid"],
 			}
 		}
+	case "refresh-wallpaper-catalog":
+		var parameters struct {
+			Rebuild bool `json:"rebuild"`
+		}
+		if len(request.Params) > 0 {
+			_ = json.Unmarshal(request.Params, &parameters)
+		}
+		refresh := wallpaper.RefreshCatalog
+		if parameters.Rebuild {
+			refresh = wallpaper.InvalidateCatalog
+		}
+		changes, err := refresh()
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Result = map[string]interface{}{"success": true, "changes": changes}
+		}
 	case "get-wallpaper-project-data":
 		var parameters struct {
 			ID string `json:"id"`
@@ -50,17 +67,31 @@ func (handler *Handler) HandleWallpaper(request models.Request) models.Response
 				response.Result = map[string]interface{}{"success": true, "properties": properties}
 			}
 		}
+	case "get-wallpaper-property-schema":
+		var parameters struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(request.Params, &parameters); err != nil {
+			response.Error = err.Error()
+		} else {
+			properties, err := wallpaper.GetWallpaperPropertySchema(parameters.ID)
+			if err != nil {
+				response.Error = err.Error()
+			} else {
+				response.Result = map[string]interface{}{"success": true, "properties": properties}
+			}
+		}
 	case "get-wallpaper-base-path":
 		if err := config.EnsureInitialized(); err != nil {
 			response.Error = err.Error()
 		} else {
-			response.Result = config.WorkshopPath
+			response.Result = config.GetWorkshopPath()
 		}
 	case "get-assets-base-path":
 		if err := config.EnsureInitialized(); err != nil {
 			response.Error = err.Error()
 		} else {
-			response.Result = config.WallpaperEnginePath
+			response.Result = config.GetWallpaperEnginePath()
 		}
 	case "kill-all-wallpapers":
 		handler.wallpaperService.KillAllWallpapers()
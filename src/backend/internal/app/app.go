@@ -36,6 +36,7 @@ func NewApp(options Options) *App {
 	processManager := process.NewManager()
 	wallpaperService := wallpaper.NewService(processManager)
 	playlistService := playlist.NewService(wallpaperService)
+	playlistService.SetEventHandler(api.BroadcastEvent)
 
 	return &App{
 		socketPath:       filepath.Join(os.TempDir(), "linux-wallpaperengine-gui.sock"),
@@ -64,7 +65,7 @@ func (application *App) Run() {
 
 	// Start components
 	application.setupDisplayWatcher()
-	application.setupFullscreenDetector()
+	application.setupWallpaperWatcher()
 	application.applyInitialWallpapers()
 	application.setupTray()
 	application.handleSignals()
@@ -117,10 +118,22 @@ func (application *App) setupDisplayWatcher() {
 	})
 }
 
+func (application *App) setupWallpaperWatcher() {
+	wallpaper.StartWallpaperWatcher(func(event string, folderName string, data *wallpaper.WallpaperData) {
+		payload := map[string]interface{}{"folderName": folderName}
+		if data != nil {
+			payload["projectData"] = data.ProjectData
+			payload["previewPath"] = data.PreviewPath
+			payload["installDate"] = data.InstallDate
+		}
+		api.BroadcastEvent(event, payload)
+	})
+}
+
 func (application *App) setupFullscreenDetector() {
 	fullscreen.StartDetector(func(isFullscreen bool) {
 		if isFullscreen {
-			application.playlistService.PausePlaylistCycle()
+			application.playlistService.PausePlaylistCycle(playlist.PauseReasonFullscreen)
 		} else {
 			application.playlistService.ResumePlaylistCycle()
 		}
@@ -138,16 +151,40 @@ func (application *App) applyInitialWallpapers() {
 		if err != nil {
 			logger.Printf("Failed to read config: %v", err)
 			notification.Error("Wallpaper Engine Error", "Failed to read config: "+err.Error())
+			application.setupFullscreenDetector()
 			return
 		}
 
-		for _, screen := range appConfig.Screens {
-			if screen.Playlist != "" {
-				if err := application.playlistService.StartPlaylistCycle(screen.Name); err != nil {
-					logger.Printf("Failed to start playlist cycle for screen %s: %v", screen.Name, err)
+		if appConfig.CloneMode || appConfig.SpanMode {
+			if appConfig.Playlist != "" {
+				if err := application.playlistService.RestorePlaylistCycle(playlist.GlobalSession); err != nil {
+					logger.Printf("Failed to start global playlist cycle: %v", err)
+				}
+			}
+		} else {
+			groupedScreens := playlist.GroupedScreens(appConfig)
+			for _, group := range appConfig.PlaylistGroups {
+				if group.Playlist != "" {
+					if err := application.playlistService.RestorePlaylistCycle(playlist.GroupSessionKey(group.Name)); err != nil {
+						logger.Printf("Failed to start playlist cycle for group %s: %v", group.Name, err)
+					}
+				}
+			}
+			for _, screen := range appConfig.Screens {
+				if _, grouped := groupedScreens[screen.Name]; grouped {
+					continue
+				}
+				if screen.Playlist != "" {
+					if err := application.playlistService.RestorePlaylistCycle(screen.Name); err != nil {
+						logger.Printf("Failed to start playlist cycle for screen %s: %v", screen.Name, err)
+					}
 				}
 			}
 		}
+
+		// Started only once sessions are restored, so that its initial report
+		// resumes sessions that were saved while paused.
+		application.setupFullscreenDetector()
 	}()
 }
 
@@ -171,6 +208,12 @@ func (application *App) setupTray() {
 				logger.Printf("Failed to apply wallpapers on restart: %v", err)
 			}
 		},
+		func() { // Next wallpaper callback
+			go application.playlistService.NextWallpaperAll()
+		},
+		func() { // Previous wallpaper callback
+			go application.playlistService.PreviousWallpaperAll()
+		},
 		func() { // Quit callback
 			application.Cleanup()
 			os.Exit(0)
@@ -191,8 +234,10 @@ func (application *App) handleSignals() {
 
 func (application *App) Cleanup() {
 	logger.Println("Performing cleanup...")
+	application.playlistService.SaveState()
 	application.processManager.KillAll()
 	fullscreen.StopDetector()
+	wallpaper.StopWallpaperWatcher()
 	electron.Stop()
 	if _, err := os.Stat(application.socketPath); err == nil {
 		if err := os.Remove(application.socketPath); err != nil {
@@ -13,11 +13,16 @@ import (
 var (
 	HomePath            string
 	ConfigPath          string
+	StateDir            string
 	AutostartPath       string
-	WorkshopPath        string
-	WallpaperEnginePath string
 	DefaultConfig       AppConfig
 
+	// The detected paths are set by EnsureInitialized and read through
+	// GetWorkshopPath and GetWallpaperEnginePath.
+	workshopPathValue        string
+	wallpaperEnginePathValue string
+	pathsMutex               sync.RWMutex
+
 	corruptConfigPrompt struct {
 		sync.Mutex
 		displayed bool
@@ -34,6 +39,12 @@ func init() {
 	ConfigPath = filepath.Join(HomePath, ".config/linux-wallpaperengine-gui/config.json")
 	AutostartPath = filepath.Join(HomePath, ".config/autostart/linux-wallpaperengine-gui.desktop")
 
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" || !filepath.IsAbs(stateHome) {
+		stateHome = filepath.Join(HomePath, ".local/state")
+	}
+	StateDir = filepath.Join(stateHome, "linux-wallpaperengine-gui")
+
 	DefaultConfig = AppConfig{
 		FPS:                 60,
 		Silence:             false,
@@ -181,67 +192,79 @@ func resolvePath(p string) string {
 	return filepath.Join(HomePath, p)
 }
 
+// EnsureInitialized detects the Wallpaper Engine and workshop paths and
+// stores them for GetWallpaperEnginePath and GetWorkshopPath. Detection
+// happens before either is touched and both change under one lock, so readers
+// never see them cleared halfway.
 func EnsureInitialized() error {
-	// Clear previous paths to allow fresh detection
-	WorkshopPath = ""
-	WallpaperEnginePath = ""
-
-	// Try to get from config first
-	conf, err := ReadConfig()
+	wallpaperEnginePath, workshopPath, err := ResolvePaths()
 	if err != nil {
 		return err
 	}
 
-	workshopSuffix := "steamapps/workshop/content/431960"
-	wallpaperEngineSuffix := "steamapps/common/wallpaper_engine"
-	steamPaths := conf.SteamPaths
-	if len(steamPaths) == 0 {
-		steamPaths = DefaultConfig.SteamPaths
+	pathsMutex.Lock()
+	defer pathsMutex.Unlock()
+	wallpaperEnginePathValue = wallpaperEnginePath
+	workshopPathValue = workshopPath
+	return nil
+}
+
+// GetWorkshopPath returns the workshop content folder found by the last
+// EnsureInitialized, or "" when there is none.
+func GetWorkshopPath() string {
+	pathsMutex.RLock()
+	defer pathsMutex.RUnlock()
+	return workshopPathValue
+}
+
+// GetWallpaperEnginePath returns the Wallpaper Engine install found by the
+// last EnsureInitialized, or "" when there is none.
+func GetWallpaperEnginePath() string {
+	pathsMutex.RLock()
+	defer pathsMutex.RUnlock()
+	return wallpaperEnginePathValue
+}
+
+// ResolvePaths works out the Wallpaper Engine and workshop paths from the
+// config and the Steam libraries without changing any global state.
+func ResolvePaths() (wallpaperEnginePath string, workshopPath string, err error) {
+	conf, err := ReadConfig()
+	if err != nil {
+		return "", "", err
 	}
 
+	libraries := DiscoverSteamLibraries(steamPathsFor(conf))
+	detectedWallpaperEnginePath, detectedWorkshopPath := selectSteamLibrary(libraries, conf.SteamLibrary)
+
 	// 1. Resolve Wallpaper Engine Path
 	if conf.WallpaperEngineDir != "" {
-		WallpaperEnginePath = resolvePath(conf.WallpaperEngineDir)
+		wallpaperEnginePath = resolvePath(conf.WallpaperEngineDir)
 	}
 
-	if WallpaperEnginePath == "" {
-		// Auto-detect from steam paths
-		for _, p := range steamPaths {
-			steamRoot := resolvePath(p)
-			fullPath := filepath.Join(steamRoot, wallpaperEngineSuffix)
-			if _, err := os.Stat(fullPath); err == nil {
-				WallpaperEnginePath = fullPath
-				break
-			}
-		}
+	if wallpaperEnginePath == "" {
+		// Auto-detect from Steam libraries
+		wallpaperEnginePath = detectedWallpaperEnginePath
 	}
 
 	// 2. Resolve Workshop Path
 	if conf.WorkshopDir != "" {
-		WorkshopPath = resolvePath(conf.WorkshopDir)
+		workshopPath = resolvePath(conf.WorkshopDir)
 	}
 
 	// Try to derive workshop from assets if still missing
-	if WorkshopPath == "" && WallpaperEnginePath != "" {
-		derived := filepath.Join(WallpaperEnginePath, "../../workshop/content/431960")
+	if workshopPath == "" && wallpaperEnginePath != "" {
+		derived := filepath.Join(wallpaperEnginePath, "../../workshop/content/431960")
 		if _, err := os.Stat(derived); err == nil {
-			WorkshopPath = derived
+			workshopPath = derived
 		}
 	}
 
-	if WorkshopPath == "" {
-		// Auto-detect from steam paths
-		for _, p := range steamPaths {
-			steamRoot := resolvePath(p)
-			fullPath := filepath.Join(steamRoot, workshopSuffix)
-			if _, err := os.Stat(fullPath); err == nil {
-				WorkshopPath = fullPath
-				break
-			}
-		}
+	if workshopPath == "" {
+		// Auto-detect from Steam libraries
+		workshopPath = detectedWorkshopPath
 	}
 
-	return nil
+	return wallpaperEnginePath, workshopPath, nil
 }
 
 func ReadConfig() (AppConfig, error) {
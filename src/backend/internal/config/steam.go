@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+const (
+	WallpaperEngineAppID  = "431960"
+	workshopSuffix        = "steamapps/workshop/content/" + WallpaperEngineAppID
+	wallpaperEngineSuffix = "steamapps/common/wallpaper_engine"
+)
+
+type SteamLibrary struct {
+	Path                string `json:"path"`
+	Label               string `json:"label,omitempty"`
+	SteamRoot           string `json:"steamRoot"`
+	OwnsWallpaperEngine bool   `json:"ownsWallpaperEngine"`
+	WorkshopPath        string `json:"workshopPath,omitempty"`
+	WallpaperEnginePath string `json:"wallpaperEnginePath,omitempty"`
+}
+
+// DiscoverSteamLibraries reads libraryfolders.vdf from every configured Steam
+// root and returns each unique library, including the roots themselves.
+func DiscoverSteamLibraries(steamPaths []string) []SteamLibrary {
+	var libraries []SteamLibrary
+	seen := make(map[string]bool)
+
+	add := func(library SteamLibrary) {
+		key := library.Path
+		if resolved, err := filepath.EvalSymlinks(library.Path); err == nil {
+			key = resolved
+		}
+		if seen[key] {
+			return
+		}
+		if _, err := os.Stat(filepath.Join(library.Path, "steamapps")); err != nil {
+			return
+		}
+		seen[key] = true
+
+		if path := filepath.Join(library.Path, workshopSuffix); isDirectory(path) {
+			library.WorkshopPath = path
+		}
+		if path := filepath.Join(library.Path, wallpaperEngineSuffix); isDirectory(path) {
+			library.WallpaperEnginePath = path
+		}
+		libraries = append(libraries, library)
+	}
+
+	for _, steamPath := range steamPaths {
+		steamRoot := resolvePath(steamPath)
+		if steamRoot == "" {
+			continue
+		}
+
+		for _, libraryFile := range []string{
+			filepath.Join(steamRoot, "steamapps", "libraryfolders.vdf"),
+			filepath.Join(steamRoot, "config", "libraryfolders.vdf"),
+		} {
+			document, err := ReadVDF(libraryFile)
+			if err != nil {
+				continue
+			}
+			for _, library := range parseLibraryFolders(document) {
+				library.SteamRoot = steamRoot
+				add(library)
+			}
+		}
+
+		add(SteamLibrary{Path: steamRoot, SteamRoot: steamRoot})
+	}
+
+	return libraries
+}
+
+func parseLibraryFolders(document *KeyValues) []SteamLibrary {
+	root := document.Child("libraryfolders")
+	if root == nil {
+		return nil
+	}
+
+	var libraries []SteamLibrary
+	for _, entry := range root.Children {
+		// Legacy format: "1" "/path/to/library"
+		if entry.Children == nil {
+			if _, err := strconv.Atoi(entry.Key); err == nil && entry.Value != "" {
+				libraries = append(libraries, SteamLibrary{Path: entry.Value})
+			}
+			continue
+		}
+
+		path := entry.String("path")
+		if path == "" {
+			continue
+		}
+		library := SteamLibrary{Path: path, Label: entry.String("label")}
+		if apps := entry.Child("apps"); apps != nil && apps.Child(WallpaperEngineAppID) != nil {
+			library.OwnsWallpaperEngine = true
+		}
+		libraries = append(libraries, library)
+	}
+
+	sort.SliceStable(libraries, func(i, j int) bool {
+		return libraries[i].OwnsWallpaperEngine && !libraries[j].OwnsWallpaperEngine
+	})
+	return libraries
+}
+
+// selectSteamLibrary picks the library that should provide Wallpaper Engine
+// and its workshop content: the user's choice first, then the library whose
+// manifest owns app 431960, then any library that has the files on disk.
+func selectSteamLibrary(libraries []SteamLibrary, preferred string) (wallpaperEnginePath, workshopPath string) {
+	if library := findSteamLibrary(libraries, preferred); library != nil {
+		wallpaperEnginePath, workshopPath = library.WallpaperEnginePath, library.WorkshopPath
+	} else {
+		for _, library := range libraries {
+			if library.OwnsWallpaperEngine && (library.WallpaperEnginePath != "" || library.WorkshopPath != "") {
+				wallpaperEnginePath, workshopPath = library.WallpaperEnginePath, library.WorkshopPath
+				break
+			}
+		}
+	}
+
+	// Whatever the chosen library lacks comes from the others.
+
+	for _, library := range libraries {
+		if wallpaperEnginePath == "" && library.WallpaperEnginePath != "" {
+			wallpaperEnginePath = library.WallpaperEnginePath
+		}
+		if workshopPath == "" && library.WorkshopPath != "" {
+			workshopPath = library.WorkshopPath
+		}
+	}
+
+	return wallpaperEnginePath, workshopPath
+}
+
+func GetSteamLibraries() ([]SteamLibrary, error) {
+	conf, err := ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return DiscoverSteamLibraries(steamPathsFor(conf)), nil
+}
+
+// findSteamLibrary returns the library at path, or nil.
+func findSteamLibrary(libraries []SteamLibrary, path string) *SteamLibrary {
+	if path == "" {
+		return nil
+	}
+	path = resolvePath(path)
+	for i := range libraries {
+		if libraries[i].Path == path {
+			return &libraries[i]
+		}
+	}
+	return nil
+}
+
+// SelectSteamLibrary makes the library at path the one Wallpaper Engine and
+// its workshop content are taken from. The library must be one Steam knows
+// and hold Wallpaper Engine; an empty path goes back to auto-detection.
+func SelectSteamLibrary(path string) error {
+	conf, err := ReadConfig()
+	if err != nil {
+		return err
+	}
+	if path != "" {
+		library := findSteamLibrary(DiscoverSteamLibraries(steamPathsFor(conf)), path)
+		if library == nil {
+			return fmt.Errorf("'%s' is not a known Steam library", path)
+		}
+		if !library.OwnsWallpaperEngine && library.WallpaperEnginePath == "" && library.WorkshopPath == "" {
+			return fmt.Errorf("Steam library '%s' does not contain Wallpaper Engine", path)
+		}
+	}
+	conf.SteamLibrary = path
+	if err := WriteConfig(conf); err != nil {
+		return err
+	}
+	return EnsureInitialized()
+}
+
+func steamPathsFor(conf AppConfig) []string {
+	if len(conf.SteamPaths) == 0 {
+		return DefaultConfig.SteamPaths
+	}
+	return conf.SteamPaths
+}
+
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,59 @@
+package config
+
+import "testing"
+
+func TestSelectSteamLibrary(t *testing.T) {
+	owner := SteamLibrary{Path: "/games", OwnsWallpaperEngine: true, WallpaperEnginePath: "/games/we", WorkshopPath: "/games/workshop"}
+	engineOnly := SteamLibrary{Path: "/fast", WallpaperEnginePath: "/fast/we"}
+	workshopOnly := SteamLibrary{Path: "/slow", WorkshopPath: "/slow/workshop"}
+	empty := SteamLibrary{Path: "/empty"}
+
+	tests := []struct {
+		name         string
+		libraries    []SteamLibrary
+		preferred    string
+		wantEngine   string
+		wantWorkshop string
+	}{
+		{
+			name:         "owner without a preference",
+			libraries:    []SteamLibrary{empty, owner, workshopOnly},
+			wantEngine:   "/games/we",
+			wantWorkshop: "/games/workshop",
+		},
+		{
+			name:         "preferred library wins",
+			libraries:    []SteamLibrary{owner, engineOnly, workshopOnly},
+			preferred:    "/slow",
+			wantEngine:   "/games/we",
+			wantWorkshop: "/slow/workshop",
+		},
+		{
+			name:         "preferred library lacking workshop content keeps another's",
+			libraries:    []SteamLibrary{engineOnly, workshopOnly},
+			preferred:    "/fast",
+			wantEngine:   "/fast/we",
+			wantWorkshop: "/slow/workshop",
+		},
+		{
+			name:         "unknown preference falls back to detection",
+			libraries:    []SteamLibrary{owner},
+			preferred:    "/gone",
+			wantEngine:   "/games/we",
+			wantWorkshop: "/games/workshop",
+		},
+		{
+			name:      "nothing installed",
+			libraries: []SteamLibrary{empty},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine, workshop := selectSteamLibrary(test.libraries, test.preferred)
+			if engine != test.wantEngine || workshop != test.wantWorkshop {
+				t.Errorf("paths = %q, %q, want %q, %q", engine, workshop, test.wantEngine, test.wantWorkshop)
+			}
+		})
+	}
+}
@@ -10,6 +10,49 @@ type ScreenConfig struct {
 	Wallpaper        *string `json:"wallpaper"`
 	Playlist         string  `json:"playlist"`
 	PlaylistInterval float64 `json:"playlistInterval,omitempty"`
+	PlaylistOrder    string  `json:"playlistOrder,omitempty"`
+	AspectPolicy     string  `json:"aspectPolicy,omitempty"`
+	// WallpaperProperties overrides property values, per wallpaper, for this
+	// screen only. Values are merged key by key: global properties, then the
+	// wallpaper's, then these.
+	WallpaperProperties map[string]map[string]string `json:"wallpaperProperties,omitempty"`
+	// Render overrides the global renderer settings for this screen.
+	Render *RenderOverrides `json:"render,omitempty"`
+}
+
+// RenderOverrides holds per-screen values for the renderer arguments in
+// AppConfig. Unset fields keep the global value.
+type RenderOverrides struct {
+	FPS               *int  `json:"FPS,omitempty"`
+	Silence           *bool `json:"SILENCE,omitempty"`
+	NoAutomute        *bool `json:"noAutomute,omitempty"`
+	NoAudioProcessing *bool `json:"noAudioProcessing,omitempty"`
+	NoFullscreenPause *bool `json:"noFullscreenPause,omitempty"`
+	DisableParticles  *bool `json:"disableParticles,omitempty"`
+
+	Scaling  *string `json:"scaling,omitempty"`
+	Clamping *string `json:"clamping,omitempty"`
+	Layer    *string `json:"layer,omitempty"`
+
+	Volume *float64 `json:"volume,omitempty"`
+
+	DisableMouse    *bool `json:"disableMouse,omitempty"`
+	DisableParallax *bool `json:"disableParallax,omitempty"`
+
+	FullscreenPauseOnlyActive   *bool     `json:"fullscreenPauseOnlyActive,omitempty"`
+	FullscreenPauseIgnoreAppIds *[]string `json:"fullscreenPauseIgnoreAppIds,omitempty"`
+}
+
+// PlaylistGroup makes several screens share one playlist clock. Every change
+// gives each screen a different item; StaggerSeconds delays each screen after
+// the first by that much more than the previous one.
+type PlaylistGroup struct {
+	Name             string   `json:"name"`
+	Screens          []string `json:"screens"`
+	Playlist         string   `json:"playlist"`
+	PlaylistInterval float64  `json:"playlistInterval,omitempty"`
+	PlaylistOrder    string   `json:"playlistOrder,omitempty"`
+	StaggerSeconds   float64  `json:"staggerSeconds,omitempty"`
 }
 
 type AppConfig struct {
@@ -29,6 +72,10 @@ type AppConfig struct {
 	Layer            string  `json:"layer,omitempty"`
 	Playlist         string  `json:"playlist"`
 	PlaylistInterval float64 `json:"playlistInterval,omitempty"`
+	PlaylistOrder    string  `json:"playlistOrder,omitempty"`
+	// AspectPolicy is how playlists match wallpapers to the screen shape:
+	// "off", "prefer" or "strict". Screens can override it.
+	AspectPolicy string `json:"aspectPolicy,omitempty"`
 
 	// Audio Settings
 	Volume *float64 `json:"volume,omitempty"`
@@ -55,23 +102,26 @@ type AppConfig struct {
 	WallpaperEngineDir string `json:"wallpaperEngineDir,omitempty"`
 
 	// --- GUI / Internal Settings ---
-	Screens                  []ScreenConfig `json:"screens,omitempty"`
-	CloneMode                bool           `json:"cloneMode,omitempty"`
-	SpanMode                 bool           `json:"spanMode,omitempty"`
-	GlobalWallpaper          *string        `json:"globalWallpaper,omitempty"`
-	CustomExecutableLocation string         `json:"customExecutableLocation,omitempty"`
-	WorkshopDir              string         `json:"workshopDir,omitempty"`
-	NativeWayland            bool           `json:"nativeWayland,omitempty"`
-	Autostart                bool           `json:"autostart"`
-	DynamicUiTheme           bool           `json:"dynamicUiTheme"`
-	DynamicSidebarTheme      bool           `json:"dynamicSidebarTheme"`
-	TransparentUi            bool           `json:"transparentUi"`
-	UiTransparency           int            `json:"uiTransparency,omitempty"`
-	SteamPaths               []string       `json:"steamPaths,omitempty"`
-	EnableScrollMask         bool           `json:"enableScrollMask"`
-	HookEnabled              bool           `json:"hookEnabled"`
-	HideTrayLabel            bool           `json:"hideTrayLabel"`
-	WallpaperChangeCommand   string         `json:"wallpaperChangeCommand,omitempty"`
+	Screens                  []ScreenConfig  `json:"screens,omitempty"`
+	CloneMode                bool            `json:"cloneMode,omitempty"`
+	SpanMode                 bool            `json:"spanMode,omitempty"`
+	GlobalWallpaper          *string         `json:"globalWallpaper,omitempty"`
+	CustomExecutableLocation string          `json:"customExecutableLocation,omitempty"`
+	WorkshopDir              string          `json:"workshopDir,omitempty"`
+	NativeWayland            bool            `json:"nativeWayland,omitempty"`
+	Autostart                bool            `json:"autostart"`
+	DynamicUiTheme           bool            `json:"dynamicUiTheme"`
+	DynamicSidebarTheme      bool            `json:"dynamicSidebarTheme"`
+	TransparentUi            bool            `json:"transparentUi"`
+	UiTransparency           int             `json:"uiTransparency,omitempty"`
+	SteamPaths               []string        `json:"steamPaths,omitempty"`
+	SteamLibrary             string          `json:"steamLibrary,omitempty"`
+	EnableScrollMask         bool            `json:"enableScrollMask"`
+	HookEnabled              bool            `json:"hookEnabled"`
+	HideTrayLabel            bool            `json:"hideTrayLabel"`
+	WallpaperChangeCommand   string          `json:"wallpaperChangeCommand,omitempty"`
+	PlaylistStore            string          `json:"playlistStore,omitempty"`
+	PlaylistGroups           []PlaylistGroup `json:"playlistGroups,omitempty"`
 
 	// Fixed Filters
 	InstalledFilters *FilterConfig `json:"installedFilters,omitempty"`
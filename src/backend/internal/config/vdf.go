@@ -0,0 +1,223 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyValues is a node of a Valve KeyValues (VDF) text document. A node holds
+// either a string Value or a list of Children, never both.
+type KeyValues struct {
+	Key      string
+	Value    string
+	Children []*KeyValues
+}
+
+// Child returns the first child whose key matches (case-insensitive), or nil.
+func (node *KeyValues) Child(key string) *KeyValues {
+	if node == nil {
+		return nil
+	}
+	for _, child := range node.Children {
+		if strings.EqualFold(child.Key, key) {
+			return child
+		}
+	}
+	return nil
+}
+
+// String returns the value of the named child, or "" when it does not exist.
+func (node *KeyValues) String(key string) string {
+	if child := node.Child(key); child != nil {
+		return child.Value
+	}
+	return ""
+}
+
+func ReadVDF(path string) (*KeyValues, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseVDF(string(data))
+}
+
+// ParseVDF parses a KeyValues text document and returns a root node whose
+// children are the top-level keys of the document.
+func ParseVDF(text string) (*KeyValues, error) {
+	parser := &vdfParser{text: text}
+	root := &KeyValues{}
+	if err := parser.parseChildren(root, false); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+type vdfParser struct {
+	text     string
+	position int
+	line     int
+}
+
+type vdfTokenKind int
+
+const (
+	vdfEOF vdfTokenKind = iota
+	vdfString
+	vdfOpenBrace
+	vdfCloseBrace
+	vdfConditional
+)
+
+func (parser *vdfParser) parseChildren(parent *KeyValues, nested bool) error {
+	for {
+		kind, key, err := parser.next()
+		if err != nil {
+			return err
+		}
+
+		switch kind {
+		case vdfEOF:
+			if nested {
+				return parser.errorf("unexpected end of file, missing '}'")
+			}
+			return nil
+		case vdfCloseBrace:
+			if !nested {
+				return parser.errorf("unexpected '}'")
+			}
+			return nil
+		case vdfOpenBrace, vdfConditional:
+			return parser.errorf("expected key")
+		}
+
+		kind, value, err := parser.next()
+		if err != nil {
+			return err
+		}
+
+		node := &KeyValues{Key: key}
+		switch kind {
+		case vdfString:
+			node.Value = value
+		case vdfOpenBrace:
+			// An empty block still has children, just none of them.
+			node.Children = []*KeyValues{}
+			if err := parser.parseChildren(node, true); err != nil {
+				return err
+			}
+		default:
+			return parser.errorf("expected value or '{' after key %q", key)
+		}
+
+		// Platform conditionals such as [$WIN32] are accepted and ignored.
+		if parser.peekConditional() {
+			if _, _, err := parser.next(); err != nil {
+				return err
+			}
+		}
+
+		parent.Children = append(parent.Children, node)
+	}
+}
+
+func (parser *vdfParser) skipWhitespaceAndComments() {
+	for parser.position < len(parser.text) {
+		character := parser.text[parser.position]
+		switch {
+		case character == '\n':
+			parser.line++
+			parser.position++
+		case character == ' ' || character == '\t' || character == '\r':
+			parser.position++
+		case strings.HasPrefix(parser.text[parser.position:], "//"):
+			for parser.position < len(parser.text) && parser.text[parser.position] != '\n' {
+				parser.position++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (parser *vdfParser) peekConditional() bool {
+	parser.skipWhitespaceAndComments()
+	return parser.position < len(parser.text) && parser.text[parser.position] == '['
+}
+
+func (parser *vdfParser) next() (vdfTokenKind, string, error) {
+	parser.skipWhitespaceAndComments()
+	if parser.position >= len(parser.text) {
+		return vdfEOF, "", nil
+	}
+
+	switch parser.text[parser.position] {
+	case '{':
+		parser.position++
+		return vdfOpenBrace, "", nil
+	case '}':
+		parser.position++
+		return vdfCloseBrace, "", nil
+	case '[':
+		end := strings.IndexByte(parser.text[parser.position:], ']')
+		if end < 0 {
+			return vdfEOF, "", parser.errorf("unterminated conditional")
+		}
+		condition := parser.text[parser.position+1 : parser.position+end]
+		parser.position += end + 1
+		return vdfConditional, condition, nil
+	case '"':
+		return parser.quoted()
+	}
+
+	start := parser.position
+	for parser.position < len(parser.text) {
+		character := parser.text[parser.position]
+		if character == ' ' || character == '\t' || character == '\r' || character == '\n' ||
+			character == '{' || character == '}' || character == '"' {
+			break
+		}
+		parser.position++
+	}
+	return vdfString, parser.text[start:parser.position], nil
+}
+
+func (parser *vdfParser) quoted() (vdfTokenKind, string, error) {
+	parser.position++ // opening quote
+	var builder strings.Builder
+	for parser.position < len(parser.text) {
+		character := parser.text[parser.position]
+		switch character {
+		case '"':
+			parser.position++
+			return vdfString, builder.String(), nil
+		case '\\':
+			if parser.position+1 < len(parser.text) {
+				parser.position++
+				switch escaped := parser.text[parser.position]; escaped {
+				case 'n':
+					builder.WriteByte('\n')
+				case 't':
+					builder.WriteByte('\t')
+				case '\\', '"':
+					builder.WriteByte(escaped)
+				default:
+					builder.WriteByte('\\')
+					builder.WriteByte(escaped)
+				}
+			}
+		case '\n':
+			parser.line++
+			builder.WriteByte(character)
+		default:
+			builder.WriteByte(character)
+		}
+		parser.position++
+	}
+	return vdfEOF, "", parser.errorf("unterminated string")
+}
+
+func (parser *vdfParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("vdf line %d: %s", parser.line+1, fmt.Sprintf(format, args...))
+}
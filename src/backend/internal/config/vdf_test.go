@@ -0,0 +1,150 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// flatten renders a parsed document as "path=value" lines so expectations
+// stay readable.
+func flatten(node *KeyValues, prefix string, lines *[]string) {
+	for _, child := range node.Children {
+		path := prefix + child.Key
+		if child.Children == nil {
+			*lines = append(*lines, path+"="+child.Value)
+			continue
+		}
+		*lines = append(*lines, path+"/")
+		flatten(child, path+"/", lines)
+	}
+}
+
+func TestParseVDF(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "nested quoted keys",
+			text: `"libraryfolders" { "0" { "path" "/home/user/.steam" "label" "" } }`,
+			want: []string{"libraryfolders/", "libraryfolders/0/", "libraryfolders/0/path=/home/user/.steam", "libraryfolders/0/label="},
+		},
+		{
+			name: "unquoted tokens and comments",
+			text: "// header\nroot\n{\n\tkey value // trailing\n\tother \"quoted value\"\n}\n",
+			want: []string{"root/", "root/key=value", "root/other=quoted value"},
+		},
+		{
+			name: "escapes",
+			text: `"key" "C:\\Program Files\\Steam \"x\"\tend"`,
+			want: []string{"key=C:\\Program Files\\Steam \"x\"\tend"},
+		},
+		{
+			name: "unknown escape is kept",
+			text: `"key" "a\qb"`,
+			want: []string{`key=a\qb`},
+		},
+		{
+			name: "conditionals are ignored",
+			text: `"root" { "key" "value" [$WIN32] "nested" { } [!$OSX] }`,
+			want: []string{"root/", "root/key=value", "root/nested/"},
+		},
+		{
+			name: "empty document",
+			text: "  \n// nothing\n",
+			want: nil,
+		},
+		{
+			name:    "missing closing brace",
+			text:    "\"root\"\n{\n\"key\" \"value\"\n",
+			wantErr: "vdf line 4: unexpected end of file",
+		},
+		{
+			name:    "stray closing brace",
+			text:    `"key" "value" }`,
+			wantErr: "unexpected '}'",
+		},
+		{
+			name:    "unterminated string",
+			text:    `"key" "value`,
+			wantErr: "unterminated string",
+		},
+		{
+			name:    "key without value",
+			text:    `"root" { "key" }`,
+			wantErr: `expected value or '{' after key "key"`,
+		},
+		{
+			name:    "brace instead of key",
+			text:    `{ }`,
+			wantErr: "expected key",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document, err := ParseVDF(test.text)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			flatten(document, "", &got)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parsed = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseLibraryFolders(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []SteamLibrary
+	}{
+		{
+			name: "current format prefers the library owning Wallpaper Engine",
+			text: `"libraryfolders" {
+				"0" { "path" "/steam" "label" "" "apps" { "228980" "1" } }
+				"1" { "path" "/games" "label" "Games" "apps" { "431960" "1" } }
+				"2" { "label" "no path" }
+			}`,
+			want: []SteamLibrary{
+				{Path: "/games", Label: "Games", OwnsWallpaperEngine: true},
+				{Path: "/steam"},
+			},
+		},
+		{
+			name: "legacy format",
+			text: `"LibraryFolders" { "TimeNextStatsReport" "1" "ContentStatsID" "-1" "1" "/mnt/steam" }`,
+			want: []SteamLibrary{{Path: "/mnt/steam"}},
+		},
+		{
+			name: "missing root",
+			text: `"other" { }`,
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document, err := ParseVDF(test.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := parseLibraryFolders(document); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("libraries = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+	"linux-wallpaperengine-gui/src/backend/internal/core/wallpaper"
+)
+
+const (
+	SortName = "name"
+	SortDate = "date"
+)
+
+var workshopFolderPattern = regexp.MustCompile(`^\d+$`)
+
+// Query selects a page of installed wallpapers. Filters default to the saved
+// installed filters, and Sort, when set, overrides the filters' sort. A zero
+// Limit returns every match.
+type Query struct {
+	Filters *config.FilterConfig `json:"filters"`
+	Search  string               `json:"search"`
+	Sort    string               `json:"sort"`
+	Offset  int                  `json:"offset"`
+	Limit   int                  `json:"limit"`
+}
+
+type QueryResult struct {
+	Wallpapers []wallpaper.Wallpaper `json:"wallpapers"`
+	Total      int                   `json:"total"`
+	Installed  int                   `json:"installed"`
+	Offset     int                   `json:"offset"`
+	Limit      int                   `json:"limit"`
+}
+
+// QueryWallpapers filters, searches, sorts and pages the installed
+// wallpapers. Filter categories are combined with AND and the tags within a
+// category with OR, as in the wallpaper grid. On top of the grid, FilterConfig
+// Type selects one wallpaper type exactly and resolution tags are checked
+// against each wallpaper's native size.
+func QueryWallpapers(query Query) (*QueryResult, error) {
+	if query.Offset < 0 || query.Limit < 0 {
+		return nil, fmt.Errorf("offset and limit must not be negative")
+	}
+
+	filters := query.Filters
+	if filters == nil {
+		saved, err := GetInstalledFilters()
+		if err != nil {
+			return nil, err
+		}
+		filters = saved
+	}
+
+	installed, err := wallpaper.GetWallpapers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallpapers: %w", err)
+	}
+
+	var active activeFilters
+	if filters != nil {
+		active = newActiveFilters(*filters)
+	}
+	terms := strings.Fields(strings.ToLower(query.Search))
+
+	var resolutions map[string]wallpaper.Resolution
+	if active.resolutions != nil {
+		ids := make([]string, 0, len(installed))
+		for folderName := range installed {
+			ids = append(ids, folderName)
+		}
+		if resolutions, err = wallpaper.GetWallpaperResolutions(ids); err != nil {
+			return nil, err
+		}
+	}
+
+	matches := []wallpaper.Wallpaper{}
+	for folderName, data := range installed {
+		if data.ProjectData == nil {
+			continue
+		}
+		if !active.matches(folderName, data.ProjectData, resolutions[folderName]) || !matchesSearch(folderName, data.ProjectData, terms) {
+			continue
+		}
+		matches = append(matches, wallpaper.Wallpaper{WallpaperData: data, FolderName: folderName})
+	}
+
+	sortKey, descending := query.Sort, false
+	if filters != nil {
+		if sortKey == "" {
+			sortKey = filters.Sort
+		}
+		descending = filters.Descending
+	}
+	sortWallpapers(matches, sortKey, descending)
+
+	result := &QueryResult{
+		Wallpapers: []wallpaper.Wallpaper{},
+		Total:      len(matches),
+		Installed:  len(installed),
+		Offset:     query.Offset,
+		Limit:      query.Limit,
+	}
+	if query.Offset < len(matches) {
+		end := len(matches)
+		if query.Limit > 0 {
+			end = min(end, query.Offset+query.Limit)
+		}
+		result.Wallpapers = matches[query.Offset:end]
+	}
+	return result, nil
+}
+
+// activeFilters holds the enabled tags of each category, lower-cased. A nil
+// slice means the category does not filter at all.
+type activeFilters struct {
+	wallpaperType string
+	types         []string
+	genres        []string
+	ratings       []string
+	resolutions   []string
+	categories    []string
+	sources       []string
+	utilities     []string
+}
+
+func newActiveFilters(filters config.FilterConfig) activeFilters {
+	active := activeFilters{
+		wallpaperType: strings.ToLower(filters.Type),
+		types:         enabledTags(filters.TypeTags),
+		genres:        enabledTags(filters.Tags),
+		ratings:       enabledTags(filters.RatingTags),
+		categories:    enabledTags(filters.CategoryTags),
+		sources:       enabledTags(filters.SourceTags),
+		utilities:     enabledTags(filters.UtilityTags),
+	}
+	// Working out resolutions means probing every wallpaper, so it is only
+	// done when some resolution is actually excluded.
+	for _, enabled := range filters.ResolutionTags {
+		if !enabled {
+			active.resolutions = enabledTags(filters.ResolutionTags)
+			break
+		}
+	}
+	return active
+}
+
+func enabledTags(tags map[string]bool) []string {
+	var enabled []string
+	for tag, on := range tags {
+		if on {
+			enabled = append(enabled, strings.ToLower(tag))
+		}
+	}
+	return enabled
+}
+
+func (active activeFilters) matches(folderName string, projectData *wallpaper.WallpaperProjectData, resolution wallpaper.Resolution) bool {
+	tags := make([]string, 0, len(projectData.Tags))
+	for _, tag := range projectData.Tags {
+		tags = append(tags, strings.ToLower(tag))
+	}
+	wallpaperType := strings.ToLower(projectData.Type)
+
+	if active.wallpaperType != "" && wallpaperType != active.wallpaperType {
+		return false
+	}
+	if active.types != nil && !contains(active.types, wallpaperType) {
+		return false
+	}
+
+	if active.ratings != nil {
+		// Local wallpapers usually carry no rating and count as everyone.
+		contentRating := strings.ToLower(projectData.ContentRating)
+		if contentRating == "" {
+			contentRating = "everyone"
+		}
+		if !contains(active.ratings, contentRating) {
+			return false
+		}
+	}
+
+	if active.sources != nil {
+		source := "local"
+		if projectData.WorkshopID != "" || workshopFolderPattern.MatchString(folderName) {
+			source = "workshop"
+		}
+		if !contains(active.sources, source) {
+			return false
+		}
+	}
+
+	if active.utilities != nil {
+		others := []string{}
+		for _, tag := range active.utilities {
+			if tag == "approved" {
+				if !projectData.Approved {
+					return false
+				}
+				continue
+			}
+			others = append(others, tag)
+		}
+		if len(others) > 0 && !containsAny(others, tags) {
+			return false
+		}
+	}
+
+	// Installed items are wallpapers unless tagged otherwise, such as presets.
+	if active.categories != nil && !contains(active.categories, "wallpaper") && !containsAny(active.categories, tags) {
+		return false
+	}
+
+	if active.genres != nil {
+		// "Unspecified" stands for wallpapers without any tag.
+		unspecified := len(tags) == 0 && contains(active.genres, "unspecified")
+		if !unspecified && !containsAny(active.genres, tags) {
+			return false
+		}
+	}
+
+	if active.resolutions != nil && !contains(active.resolutions, strings.ToLower(resolutionTag(resolution))) && !containsAny(active.resolutions, tags) {
+		return false
+	}
+	return true
+}
+
+// resolutionTag names a wallpaper's resolution the way the workshop's
+// resolution filter does.
+func resolutionTag(resolution wallpaper.Resolution) string {
+	if !resolution.Known() {
+		return "Dynamic resolution"
+	}
+
+	width, height := resolution.Width, resolution.Height
+	prefix := ""
+	switch aspect := resolution.AspectRatio(); {
+	case height > width:
+		prefix = "Portrait "
+	case aspect >= 4.5:
+		prefix = "Triple "
+	case aspect >= 3.2:
+		prefix = "Dual "
+	case aspect >= 2.2:
+		prefix = "Ultrawide "
+	}
+
+	switch tag := fmt.Sprintf("%s%d x %d", prefix, width, height); tag {
+	case "1280 x 720", "1366 x 768", "1920 x 1080", "2560 x 1440", "3840 x 2160",
+		"Ultrawide 2560 x 1080", "Ultrawide 3440 x 1440",
+		"Dual 3840 x 1080", "Dual 5120 x 1440", "Dual 7680 x 2160",
+		"Triple 4096 x 768", "Triple 5760 x 1080", "Triple 7680 x 1440", "Triple 11520 x 2160",
+		"Portrait 720 x 1280", "Portrait 1080 x 1920", "Portrait 1440 x 2560", "Portrait 2160 x 3840":
+		return tag
+	}
+	if min(width, height) < 720 {
+		return prefix + "Standard Definition"
+	}
+	return "Other resolution"
+}
+
+// matchesSearch requires every search term to appear in the title,
+// description, a tag or the folder name.
+func matchesSearch(folderName string, projectData *wallpaper.WallpaperProjectData, terms []string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	text := strings.ToLower(strings.Join(append([]string{folderName, projectData.Title, projectData.Description}, projectData.Tags...), "\n"))
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortWallpapers orders by name or install date. The wallpaper grid's
+// "name-asc", "date-desc" and similar keys carry their own direction.
+func sortWallpapers(wallpapers []wallpaper.Wallpaper, sortKey string, descending bool) {
+	sortKey = strings.ToLower(sortKey)
+	if key, direction, ok := strings.Cut(sortKey, "-"); ok && (direction == "asc" || direction == "desc") {
+		sortKey, descending = key, direction == "desc"
+	}
+
+	name := func(item wallpaper.Wallpaper) string {
+		if item.ProjectData.Title != "" {
+			return strings.ToLower(item.ProjectData.Title)
+		}
+		return strings.ToLower(item.FolderName)
+	}
+	byName := func(a, b wallpaper.Wallpaper) int {
+		return strings.Compare(name(a)+"\x00"+a.FolderName, name(b)+"\x00"+b.FolderName)
+	}
+
+	compare := byName
+	if sortKey == SortDate || sortKey == "installed" {
+		compare = func(a, b wallpaper.Wallpaper) int {
+			switch {
+			case a.InstallDate < b.InstallDate:
+				return -1
+			case a.InstallDate > b.InstallDate:
+				return 1
+			}
+			return byName(a, b)
+		}
+	}
+
+	sort.Slice(wallpapers, func(i, j int) bool {
+		if descending {
+			return compare(wallpapers[j], wallpapers[i]) < 0
+		}
+		return compare(wallpapers[i], wallpapers[j]) < 0
+	})
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(wanted []string, values []string) bool {
+	for _, value := range values {
+		if contains(wanted, value) {
+			return true
+		}
+	}
+	return false
+}
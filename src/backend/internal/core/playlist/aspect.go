@@ -0,0 +1,166 @@
+package playlist
+
+import (
+	"math"
+	"strings"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+	"linux-wallpaperengine-gui/src/backend/internal/core/wallpaper"
+	"linux-wallpaperengine-gui/src/backend/internal/logger"
+	"linux-wallpaperengine-gui/src/backend/internal/platform/display"
+)
+
+const (
+	// AspectOff ignores the screen's shape.
+	AspectOff = "off"
+	// AspectPrefer makes random playback favor wallpapers that fit the screen.
+	AspectPrefer = "prefer"
+	// AspectStrict only plays wallpapers that fit the screen, unless none do.
+	AspectStrict = "strict"
+)
+
+const (
+	// aspectTolerance is how far apart two aspect ratios may be and still fit,
+	// so 16:9 and 16:10 match while 21:9 and 16:9 do not.
+	aspectTolerance = 1.2
+	// preferWeight multiplies the shuffle weight of fitting wallpapers.
+	preferWeight = 4
+)
+
+func normalizeAspectPolicy(policy string) string {
+	switch strings.ToLower(strings.TrimSpace(policy)) {
+	case AspectPrefer:
+		return AspectPrefer
+	case AspectStrict:
+		return AspectStrict
+	}
+	return AspectOff
+}
+
+// aspectPolicyFor returns the policy and screen aspect ratio of a session.
+// Sessions whose screen size is unknown, and playlist groups, are off.
+func aspectPolicyFor(appConfig config.AppConfig, sessionKey string) (string, float64) {
+	if isGroupSession(sessionKey) {
+		return AspectOff, 0
+	}
+
+	policy := appConfig.AspectPolicy
+	for _, screen := range appConfig.Screens {
+		if screen.Name == sessionKey && screen.AspectPolicy != "" {
+			policy = screen.AspectPolicy
+		}
+	}
+	policy = normalizeAspectPolicy(policy)
+	if policy == AspectOff {
+		return AspectOff, 0
+	}
+
+	screens, err := display.GetScreenInfo()
+	if err != nil {
+		logger.Printf("Ignoring aspect policy for screen %s: %v", sessionKey, err)
+		return AspectOff, 0
+	}
+
+	width, height := screenSize(appConfig, screens, sessionKey)
+	if width <= 0 || height <= 0 {
+		return AspectOff, 0
+	}
+	return policy, float64(width) / float64(height)
+}
+
+// screenSize returns the area a session draws on: the screen itself, the
+// primary screen in clone mode, or the bounding box of all screens in span
+// mode.
+func screenSize(appConfig config.AppConfig, screens []display.ScreenInfo, sessionKey string) (int, int) {
+	if sessionKey == GlobalSession {
+		if appConfig.SpanMode {
+			minX, minY, maxX, maxY := math.MaxInt, math.MaxInt, math.MinInt, math.MinInt
+			for _, screen := range screens {
+				if screen.Width == 0 {
+					continue
+				}
+				minX, minY = min(minX, screen.X), min(minY, screen.Y)
+				maxX, maxY = max(maxX, screen.X+screen.Width), max(maxY, screen.Y+screen.Height)
+			}
+			if maxX < minX {
+				return 0, 0
+			}
+			return maxX - minX, maxY - minY
+		}
+		for _, screen := range screens {
+			if screen.Primary && screen.Width > 0 {
+				return screen.Width, screen.Height
+			}
+		}
+		for _, screen := range screens {
+			if screen.Width > 0 {
+				return screen.Width, screen.Height
+			}
+		}
+		return 0, 0
+	}
+
+	for _, screen := range screens {
+		if screen.Name == sessionKey {
+			return screen.Width, screen.Height
+		}
+	}
+	return 0, 0
+}
+
+// fitsAspect reports whether a wallpaper suits a screen: same orientation and
+// a close aspect ratio. Wallpapers of unknown size adapt to the screen.
+func fitsAspect(resolution wallpaper.Resolution, screenAspect float64) bool {
+	if !resolution.Known() || screenAspect <= 0 {
+		return true
+	}
+	aspect := resolution.AspectRatio()
+	if orientation(aspect) != orientation(screenAspect) {
+		return false
+	}
+	return math.Max(aspect, screenAspect)/math.Min(aspect, screenAspect) <= aspectTolerance
+}
+
+func orientation(aspect float64) string {
+	switch {
+	case aspect > 1.1:
+		return "landscape"
+	case aspect < 1/1.1:
+		return "portrait"
+	}
+	return "square"
+}
+
+// fitScreen records which wallpapers fit the session's screen and, under the
+// strict policy, narrows the candidates to them. When nothing fits, every
+// wallpaper is kept so the screen is not left without one.
+func (session *Session) fitScreen(wallpaperIDs []string) []string {
+	session.aspectFit = nil
+	if session.AspectPolicy == AspectOff || session.AspectPolicy == "" {
+		return wallpaperIDs
+	}
+
+	resolutions, err := wallpaper.GetWallpaperResolutions(wallpaperIDs)
+	if err != nil {
+		logger.Printf("Ignoring aspect policy for screen %s: %v", session.ScreenName, err)
+		return wallpaperIDs
+	}
+
+	session.aspectFit = make(map[string]bool, len(wallpaperIDs))
+	fitting := []string{}
+	for _, wallpaperID := range wallpaperIDs {
+		if fitsAspect(resolutions[wallpaperID], session.screenAspect) {
+			session.aspectFit[wallpaperID] = true
+			fitting = append(fitting, wallpaperID)
+		}
+	}
+
+	if session.AspectPolicy != AspectStrict {
+		return wallpaperIDs
+	}
+	if len(fitting) == 0 {
+		logger.Printf("No wallpaper fits screen %s, ignoring strict aspect policy", session.ScreenName)
+		return wallpaperIDs
+	}
+	return fitting
+}
@@ -1,161 +1,105 @@
 package playlist
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
 	"math"
-
-	"linux-wallpaperengine-gui/src/backend/internal/core/wallpaper"
 )
 
 func GetPlaylists() ([]Playlist, error) {
-	configPath, err := wallpaper.GetWEConfigPath()
+	store, err := ActiveStore()
 	if err != nil {
 		return nil, err
 	}
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config.json: %w", err)
-	}
-
-	var weConfig WallpaperEngineConfig
-	if err := json.Unmarshal(data, &weConfig); err != nil {
-		return nil, fmt.Errorf("failed to parse config.json: %w", err)
-	}
-
-	return weConfig.SteamUser.General.Playlists, nil
+	return store.Load()
 }
 
 func CreatePlaylist(name string) error {
-	playlists, err := GetPlaylists()
-	if err != nil {
-		return err
-	}
-	playlists = append(playlists, Playlist{
-		Name:  name,
-		Items: []string{},
-		Settings: PlaylistSettings{
-			Delay:         60,
-			Mode:          "timer",
-			Order:         "random",
-			Transition:    false,
-			UpdateOnPause: false,
-			VideoSequence: false,
-		},
+	return UpdatePlaylists(func(playlists []Playlist) ([]Playlist, error) {
+		if err := checkPlaylistName(name); err != nil {
+			return nil, err
+		}
+		return append(playlists, Playlist{
+			Name:  name,
+			Items: []string{},
+			Settings: PlaylistSettings{
+				Delay:         60,
+				Mode:          "timer",
+				Order:         "random",
+				Transition:    false,
+				UpdateOnPause: false,
+				VideoSequence: false,
+			},
+		}), nil
 	})
-	return SavePlaylists(playlists)
 }
 
 func RenamePlaylist(oldName, newName string) error {
-	playlists, err := GetPlaylists()
-	if err != nil {
-		return err
-	}
-	for i := range playlists {
-		if playlists[i].Name == oldName {
-			playlists[i].Name = newName
-			break
+	return UpdatePlaylists(func(playlists []Playlist) ([]Playlist, error) {
+		if newName != oldName {
+			if err := checkPlaylistName(newName); err != nil {
+				return nil, err
+			}
 		}
-	}
-	return SavePlaylists(playlists)
+		for i := range playlists {
+			if playlists[i].Name == oldName {
+				playlists[i].Name = newName
+				break
+			}
+		}
+		return playlists, nil
+	})
 }
 
 func DeletePlaylist(name string) error {
-	playlists, err := GetPlaylists()
-	if err != nil {
-		return err
-	}
-	newPlaylists := []Playlist{}
-	for _, p := range playlists {
-		if p.Name != name {
-			newPlaylists = append(newPlaylists, p)
+	return UpdatePlaylists(func(playlists []Playlist) ([]Playlist, error) {
+		newPlaylists := []Playlist{}
+		for _, p := range playlists {
+			if p.Name != name {
+				newPlaylists = append(newPlaylists, p)
+			}
 		}
-	}
-	return SavePlaylists(newPlaylists)
+		return newPlaylists, nil
+	})
 }
 
 func UpdatePlaylistItems(name string, items []string) error {
-	playlists, err := GetPlaylists()
-	if err != nil {
-		return err
-	}
-	for i := range playlists {
-		if playlists[i].Name == name {
-			playlists[i].Items = items
-			break
+	return UpdatePlaylists(func(playlists []Playlist) ([]Playlist, error) {
+		for i := range playlists {
+			if playlists[i].Name == name {
+				playlists[i].Items = items
+				break
+			}
 		}
-	}
-	return SavePlaylists(playlists)
+		return playlists, nil
+	})
 }
 
 func UpdatePlaylistIntervalConfig(name string, intervalMinutes float64) error {
-	playlists, err := GetPlaylists()
-	if err != nil {
-		return err
-	}
-	for i := range playlists {
-		if playlists[i].Name == name {
-			seconds := int(math.Round(intervalMinutes * 60))
-			if intervalMinutes > 0 && seconds == 0 {
-				seconds = 1
+	return UpdatePlaylists(func(playlists []Playlist) ([]Playlist, error) {
+		for i := range playlists {
+			if playlists[i].Name == name {
+				seconds := int(math.Round(intervalMinutes * 60))
+				if intervalMinutes > 0 && seconds == 0 {
+					seconds = 1
+				}
+				playlists[i].Settings.Delay = seconds
+				break
 			}
-			playlists[i].Settings.Delay = seconds
-			break
 		}
-	}
-	return SavePlaylists(playlists)
+		return playlists, nil
+	})
 }
 
-func SavePlaylists(playlists []Playlist) error {
-	configPath, err := wallpaper.GetWEConfigPath()
+// UpdatePlaylists changes the active store's playlists through Store.Update,
+// so the change is made to what is stored right now rather than to an earlier
+// read. It holds smartPlaylistMutex throughout, so the change can check names
+// against the smart playlists with checkPlaylistName.
+func UpdatePlaylists(change func(playlists []Playlist) ([]Playlist, error)) error {
+	store, err := ActiveStore()
 	if err != nil {
 		return err
 	}
 
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return fmt.Errorf("failed to read config.json: %w", err)
-	}
-
-	// Unmarshal into generic map to preserve all other fields
-	var root map[string]interface{}
-	if err := json.Unmarshal(data, &root); err != nil {
-		return fmt.Errorf("failed to parse config.json: %w", err)
-	}
-
-	steamUser, ok := root["steamuser"].(map[string]interface{})
-	if !ok {
-		steamUser = make(map[string]interface{})
-		root["steamuser"] = steamUser
-	}
-
-	general, ok := steamUser["general"].(map[string]interface{})
-	if !ok {
-		general = make(map[string]interface{})
-		steamUser["general"] = general
-	}
-
-	// Convert playlists slice into what json.Unmarshal would produce
-	// for generic map[string]interface{} so we can seamlessly insert it
-	pBytes, err := json.Marshal(playlists)
-	if err != nil {
-		return fmt.Errorf("failed to marshal playlists: %w", err)
-	}
-
-	var genericPlaylists interface{}
-	if err := json.Unmarshal(pBytes, &genericPlaylists); err != nil {
-		return fmt.Errorf("failed to prepare playlists for save: %w", err)
-	}
-
-	general["playlists"] = genericPlaylists
-
-	// Marshal back with indentation matching WE
-	newData, err := json.MarshalIndent(root, "", "\t")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config.json: %w", err)
-	}
-
-	return os.WriteFile(configPath, newData, 0644)
+	smartPlaylistMutex.Lock()
+	defer smartPlaylistMutex.Unlock()
+	return store.Update(change)
 }
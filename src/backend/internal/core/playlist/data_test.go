@@ -0,0 +1,101 @@
+package playlist
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+)
+
+func TestPlaylistNamesDoNotShadowSmartPlaylists(t *testing.T) {
+	tests := []struct {
+		name    string
+		write   func() error
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "create",
+			write: func() error { return CreatePlaylist("evening") },
+			want:  []string{"day", "evening"},
+		},
+		{
+			name:    "create as built-in",
+			write:   func() error { return CreatePlaylist(randomAllPlaylist) },
+			want:    []string{"day"},
+			wantErr: "built-in playlist",
+		},
+		{
+			name:    "create as smart playlist",
+			write:   func() error { return CreatePlaylist("favorites") },
+			want:    []string{"day"},
+			wantErr: "smart playlist named 'favorites'",
+		},
+		{
+			name:    "rename to smart playlist",
+			write:   func() error { return RenamePlaylist("day", "favorites") },
+			want:    []string{"day"},
+			wantErr: "smart playlist named 'favorites'",
+		},
+		{
+			name:  "rename to itself",
+			write: func() error { return RenamePlaylist("day", "day") },
+			want:  []string{"day"},
+		},
+		{
+			name: "copy a smart playlist's name",
+			write: func() error {
+				source := nativeStore{path: filepath.Join(filepath.Dir(config.ConfigPath), "other.json")}
+				if err := source.write([]Playlist{{Name: "favorites", Items: []string{}}}); err != nil {
+					return err
+				}
+				_, err := CopyPlaylists(source, NativeStore(), nil, false)
+				return err
+			},
+			want:    []string{"day"},
+			wantErr: "smart playlist named 'favorites'",
+		},
+		{
+			name: "save smart playlist as regular playlist",
+			write: func() error {
+				return SaveSmartPlaylist(SmartPlaylist{Name: "day"})
+			},
+			want:    []string{"day"},
+			wantErr: "a playlist named 'day' already exists",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			directory := t.TempDir()
+			config.ConfigPath = filepath.Join(directory, "config.json")
+			if err := config.WriteConfig(config.AppConfig{FPS: 60, PlaylistStore: StoreNative}); err != nil {
+				t.Fatal(err)
+			}
+			if err := writeSmartPlaylists([]SmartPlaylist{{Name: "favorites"}}); err != nil {
+				t.Fatal(err)
+			}
+			if err := CreatePlaylist("day"); err != nil {
+				t.Fatal(err)
+			}
+
+			err := test.write()
+			if test.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("error = %v, want %q", err, test.wantErr)
+			}
+
+			playlists, err := GetPlaylists()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := playlistNames(playlists); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("playlists = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
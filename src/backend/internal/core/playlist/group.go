@@ -0,0 +1,117 @@
+package playlist
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+	"linux-wallpaperengine-gui/src/backend/internal/logger"
+)
+
+// groupSessionPrefix marks session keys that drive a playlist group instead of
+// a single screen.
+const groupSessionPrefix = "group:"
+
+// GroupSessionKey returns the session key of a playlist group, which is also
+// what IPC callers pass as the screen name to address the group.
+func GroupSessionKey(groupName string) string {
+	return groupSessionPrefix + groupName
+}
+
+func isGroupSession(sessionKey string) bool {
+	return strings.HasPrefix(sessionKey, groupSessionPrefix)
+}
+
+func findPlaylistGroup(appConfig config.AppConfig, sessionKey string) (*config.PlaylistGroup, error) {
+	groupName := strings.TrimPrefix(sessionKey, groupSessionPrefix)
+	for i := range appConfig.PlaylistGroups {
+		if appConfig.PlaylistGroups[i].Name == groupName {
+			return &appConfig.PlaylistGroups[i], nil
+		}
+	}
+	return nil, fmt.Errorf("playlist group '%s' not found in config", groupName)
+}
+
+// GroupedScreens returns the screens that belong to a playlist group with a
+// playlist assigned; their own playlists are not run while the group is.
+func GroupedScreens(appConfig config.AppConfig) map[string]string {
+	grouped := make(map[string]string)
+	for _, group := range appConfig.PlaylistGroups {
+		if group.Playlist == "" {
+			continue
+		}
+		for _, screenName := range group.Screens {
+			grouped[screenName] = GroupSessionKey(group.Name)
+		}
+	}
+	return grouped
+}
+
+// groupItems picks one item per screen, starting with primary. Items come from
+// the playlist order, so they differ as long as the playlist has enough of
+// them. Current ends on the last pick, letting the next change continue from
+// there.
+func (session *Session) groupItems(primary string) []string {
+	items := []string{primary}
+	picked := map[string]bool{primary: true}
+	distinct := make(map[string]bool, len(session.Wallpapers))
+	for _, wallpaperID := range session.Wallpapers {
+		distinct[wallpaperID] = true
+	}
+
+	session.Current = primary
+	for attempts := 0; len(items) < len(session.Screens); attempts++ {
+		candidate := session.nextWallpaper()
+		if picked[candidate] && len(picked) < len(distinct) && attempts < 2*len(session.Wallpapers) {
+			continue
+		}
+		items = append(items, candidate)
+		picked[candidate] = true
+	}
+	session.Current = items[len(items)-1]
+	return items
+}
+
+// showGroupWallpapers assigns a different item to every screen of the group.
+// Without a stagger all screens change in one config write and one
+// ApplyWallpapers call; with one, each later screen follows after its offset.
+func (service *Service) showGroupWallpapers(session *Session, primary string) error {
+	for _, timer := range session.staggerTimers {
+		timer.Stop()
+	}
+	session.staggerTimers = nil
+
+	items := session.groupItems(primary)
+	assignments := make(map[string]string, len(items))
+	for i, screenName := range session.Screens {
+		assignments[screenName] = items[i]
+	}
+	session.Assignments = assignments
+
+	if session.Stagger <= 0 {
+		session.pending = assignments
+		logger.Printf("Showing playlist group '%s' wallpapers %v", session.ScreenName, assignments)
+		return nil
+	}
+
+	first := session.Screens[0]
+	session.pending = map[string]string{first: assignments[first]}
+	for i, screenName := range session.Screens[1:] {
+		screenName, wallpaperID := screenName, assignments[screenName]
+		delay := time.Duration(i+1) * session.Stagger
+		session.staggerTimers = append(session.staggerTimers, time.AfterFunc(delay, func() {
+			session.mutex.Lock()
+			stopped := session.stopped
+			session.mutex.Unlock()
+			if stopped {
+				return
+			}
+			if err := service.assignWallpapers(map[string]string{screenName: wallpaperID}); err != nil {
+				logger.Printf("Failed to apply staggered wallpaper on screen %s: %v", screenName, err)
+			}
+		}))
+	}
+	logger.Printf("Applying playlist group '%s' wallpapers %v, %v apart", session.ScreenName, assignments, session.Stagger)
+	return nil
+}
@@ -0,0 +1,183 @@
+package playlist
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+	"linux-wallpaperengine-gui/src/backend/internal/core/wallpaper"
+)
+
+// manifestVersion is bumped when the manifest format changes incompatibly.
+const manifestVersion = 1
+
+// PlaylistManifest is a playlist in a form that can move between machines:
+// workshop IDs instead of paths into one user's Steam library.
+type PlaylistManifest struct {
+	Version  int              `json:"version"`
+	Name     string           `json:"name"`
+	Settings PlaylistSettings `json:"settings"`
+	Items    []ManifestItem   `json:"items"`
+}
+
+type ManifestItem struct {
+	WorkshopID string `json:"workshopId"`
+	Title      string `json:"title,omitempty"`
+}
+
+// MissingItem is a manifest item that is not installed on this machine.
+type MissingItem struct {
+	WorkshopID string `json:"workshopId"`
+	Title      string `json:"title,omitempty"`
+	URL        string `json:"url"`
+}
+
+// WorkshopURL opens the item's workshop page in Steam, where it can be
+// subscribed to.
+func WorkshopURL(workshopID string) string {
+	return "steam://url/CommunityFilePage/" + workshopID
+}
+
+// ExportPlaylist builds the manifest of a playlist. Items that are not
+// published on the workshop cannot be shared and are returned as skipped.
+func ExportPlaylist(name string) (*PlaylistManifest, []string, error) {
+	playlists, err := GetPlaylists()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var selected *Playlist
+	for i := range playlists {
+		if playlists[i].Name == name {
+			selected = &playlists[i]
+			break
+		}
+	}
+	if selected == nil {
+		return nil, nil, fmt.Errorf("playlist '%s' not found", name)
+	}
+
+	installed, err := wallpaper.GetWallpapers()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get wallpapers: %w", err)
+	}
+	references, err := ResolveItemMap(selected.Items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manifest := &PlaylistManifest{
+		Version:  manifestVersion,
+		Name:     selected.Name,
+		Settings: selected.Settings,
+		Items:    []ManifestItem{},
+	}
+	skipped := []string{}
+	for _, item := range selected.Items {
+		workshopID, title := manifestItemFor(item, references, installed)
+		if workshopID == "" {
+			skipped = append(skipped, item)
+			continue
+		}
+		manifest.Items = append(manifest.Items, ManifestItem{WorkshopID: workshopID, Title: title})
+	}
+	return manifest, skipped, nil
+}
+
+// manifestItemFor finds the workshop ID of a playlist item. Workshop paths
+// carry it even when the item is not installed; local projects carry it in
+// their project.json once they have been published. References holds the
+// resolved items and installed the catalog, so nothing is rescanned per item.
+func manifestItemFor(item string, references map[string]string, installed map[string]wallpaper.WallpaperData) (string, string) {
+	normalized := strings.ReplaceAll(strings.TrimSpace(item), `\`, "/")
+
+	workshopID := ""
+	if workshopIDPattern.MatchString(normalized) {
+		workshopID = normalized
+	} else if matches := workshopItemPattern.FindStringSubmatch(normalized); matches != nil {
+		workshopID = matches[1]
+	} else if reference, ok := references[item]; ok {
+		if filepath.IsAbs(reference) {
+			if project, err := wallpaper.ReadWallpaperInfo(reference); err == nil && project.WorkshopID != "" {
+				return string(project.WorkshopID), project.Title
+			}
+			return "", ""
+		}
+		workshopID = reference
+	}
+
+	if workshopID == "" {
+		return "", ""
+	}
+	title := ""
+	if data, ok := installed[workshopID]; ok && data.ProjectData != nil {
+		title = data.ProjectData.Title
+	}
+	return workshopID, title
+}
+
+// ImportPlaylist adds the manifest's playlist to the active store, pointing
+// its items at the local workshop folder. Items that are not installed are
+// kept, so they start playing once subscribed, and reported as missing. The
+// manifest name is used unless name is given; an existing playlist of that
+// name is only replaced when overwrite is set.
+func ImportPlaylist(manifest PlaylistManifest, name string, overwrite bool) (string, []MissingItem, error) {
+	if manifest.Version > manifestVersion {
+		return "", nil, fmt.Errorf("playlist manifest version %d is not supported", manifest.Version)
+	}
+	if name == "" {
+		name = manifest.Name
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, fmt.Errorf("playlist name is required")
+	}
+
+	if err := config.EnsureInitialized(); err != nil {
+		return "", nil, err
+	}
+	workshopPath := config.GetWorkshopPath()
+	if workshopPath == "" {
+		return "", nil, fmt.Errorf("workshop folder is not configured")
+	}
+
+	items := []string{}
+	missing := []MissingItem{}
+	for _, manifestItem := range manifest.Items {
+		workshopID := strings.TrimSpace(manifestItem.WorkshopID)
+		if !workshopIDPattern.MatchString(workshopID) {
+			return "", nil, fmt.Errorf("invalid workshop ID '%s' in manifest", manifestItem.WorkshopID)
+		}
+		folder := filepath.Join(workshopPath, workshopID)
+		items = append(items, filepath.Join(folder, "project.json"))
+		if !isDirectory(folder) {
+			missing = append(missing, MissingItem{
+				WorkshopID: workshopID,
+				Title:      manifestItem.Title,
+				URL:        WorkshopURL(workshopID),
+			})
+		}
+	}
+
+	imported := Playlist{Name: name, Items: items, Settings: manifest.Settings}
+	err := UpdatePlaylists(func(playlists []Playlist) ([]Playlist, error) {
+		if err := checkPlaylistName(name); err != nil {
+			return nil, err
+		}
+		for i := range playlists {
+			if playlists[i].Name == name {
+				if !overwrite {
+					return nil, fmt.Errorf("a playlist named '%s' already exists", name)
+				}
+				playlists[i] = imported
+				return playlists, nil
+			}
+		}
+		return append(playlists, imported), nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return name, missing, nil
+}
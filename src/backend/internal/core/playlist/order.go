@@ -0,0 +1,202 @@
+package playlist
+
+import (
+	"math/rand"
+	"strings"
+
+	"linux-wallpaperengine-gui/src/backend/internal/core/rating"
+	"linux-wallpaperengine-gui/src/backend/internal/logger"
+)
+
+const (
+	OrderRandom     = "random"
+	OrderSequential = "sequential"
+)
+
+// order normalizes the playlist's order setting. Wallpaper Engine only knows
+// "random" and "sequential"; anything unrecognized falls back to random.
+func (session *Session) order() string {
+	if session.Playlist == nil {
+		return OrderRandom
+	}
+	switch strings.ToLower(strings.TrimSpace(session.Playlist.Settings.Order)) {
+	case OrderSequential, "order", "ordered":
+		return OrderSequential
+	}
+	return OrderRandom
+}
+
+// nextWallpaper advances the session to its next item and returns it.
+func (session *Session) nextWallpaper() string {
+	var wallpaperID string
+	if session.order() == OrderSequential {
+		wallpaperID = session.nextSequential()
+	} else {
+		wallpaperID = session.nextShuffled()
+	}
+	session.Current = wallpaperID
+	return wallpaperID
+}
+
+// nextSequential follows the Items order. The current item is looked up by
+// value first so that edits to the playlist do not reset playback.
+func (session *Session) nextSequential() string {
+	count := len(session.Wallpapers)
+	next := 0
+	if session.Current != "" {
+		index := indexOf(session.Wallpapers, session.Current)
+		if index < 0 {
+			// The current item was removed; continue from where it used to be.
+			index = session.Position - 1
+		}
+		next = (index + 1) % count
+	}
+	session.Position = next
+	return session.Wallpapers[next]
+}
+
+// nextShuffled draws from a weighted shuffle bag: every item is played as many
+// times as its rating weight before the bag is refilled, the same item never
+// plays twice in a row when avoidable, and a refill never starts with the item
+// that just played.
+func (session *Session) nextShuffled() string {
+	session.ShuffleBag = retainAvailable(session.ShuffleBag, session.Wallpapers)
+
+	if len(session.ShuffleBag) == 0 {
+		session.ShuffleBag = weightedBag(session.Wallpapers, session.shuffleWeights(), session.Current)
+	}
+
+	wallpaperID := session.ShuffleBag[0]
+	session.ShuffleBag = session.ShuffleBag[1:]
+	if index := indexOf(session.Wallpapers, wallpaperID); index >= 0 {
+		session.Position = index
+	}
+	return wallpaperID
+}
+
+// shuffleWeights combines each item's rating weight with the aspect policy,
+// reduced by their common divisor so that an unrated playlist gets a plain
+// one-each bag.
+func (session *Session) shuffleWeights() map[string]int {
+	ratings, err := rating.GetRatings()
+	if err != nil {
+		logger.Printf("Ignoring wallpaper ratings: %v", err)
+		ratings = nil
+	}
+
+	weights := make(map[string]int, len(session.Wallpapers))
+	divisor := 0
+	for _, item := range session.Wallpapers {
+		weight := ratings[rating.Key(item)].Weight()
+		if session.AspectPolicy == AspectPrefer && session.aspectFit[item] {
+			weight *= preferWeight
+		}
+		weights[item] = weight
+		divisor = gcd(divisor, weight)
+	}
+	if divisor > 1 {
+		for item := range weights {
+			weights[item] /= divisor
+		}
+	}
+	return weights
+}
+
+func gcd(a int, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// weightedBag lays out every item weight times in random order. Each draw is
+// proportional to the copies left, except that the previous item is skipped
+// and an item that would otherwise be forced into back-to-back plays is
+// placed first.
+func weightedBag(items []string, weights map[string]int, previous string) []string {
+	remaining := make(map[string]int, len(items))
+	unique := make([]string, 0, len(items))
+	total := 0
+	for _, item := range items {
+		if _, seen := remaining[item]; seen {
+			continue
+		}
+		weight := weights[item]
+		if weight < 1 {
+			weight = 1
+		}
+		remaining[item] = weight
+		unique = append(unique, item)
+		total += weight
+	}
+
+	bag := make([]string, 0, total)
+	for total > 0 {
+		var pick string
+		candidates := 0
+		for _, item := range unique {
+			count := remaining[item]
+			if count == 0 || item == previous {
+				continue
+			}
+			if count > total-count {
+				pick = item
+				break
+			}
+			candidates += count
+		}
+
+		if pick == "" && candidates > 0 {
+			draw := rand.Intn(candidates)
+			for _, item := range unique {
+				count := remaining[item]
+				if count == 0 || item == previous {
+					continue
+				}
+				if draw < count {
+					pick = item
+					break
+				}
+				draw -= count
+			}
+		}
+		if pick == "" {
+			// Only the previous item is left, so a repeat cannot be avoided.
+			pick = previous
+		}
+
+		bag = append(bag, pick)
+		remaining[pick]--
+		total--
+		previous = pick
+	}
+	return bag
+}
+
+// retainAvailable drops bag entries that are no longer part of the playlist,
+// e.g. after an item was removed or a wallpaper was unsubscribed.
+func retainAvailable(bag []string, available []string) []string {
+	if len(bag) == 0 {
+		return bag
+	}
+	availableSet := make(map[string]bool, len(available))
+	for _, item := range available {
+		availableSet[item] = true
+	}
+	result := bag[:0]
+	for _, item := range bag {
+		if availableSet[item] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func indexOf(items []string, value string) int {
+	for i, item := range items {
+		if item == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -2,8 +2,8 @@ package playlist
 
 import (
 	"fmt"
-	"math/rand"
-	"regexp"
+	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -12,21 +12,12 @@ import (
 	"linux-wallpaperengine-gui/src/backend/internal/logger"
 )
 
-type Session struct {
-	Timer      *time.Ticker
-	StopChan   chan bool
-	PauseChan  chan bool
-	ResumeChan chan bool
-	UpdateChan chan float64
-	Paused     bool
-	Playlist   *Playlist
-	Wallpapers []string
-}
-
 type Service struct {
 	wallpaperService       *wallpaper.Service
 	activePlaylistSessions map[string]*Session
 	mutex                  sync.Mutex
+	configMutex            sync.Mutex
+	eventHandler           EventHandler
 }
 
 func NewService(wallpaperService *wallpaper.Service) *Service {
@@ -36,136 +27,355 @@ func NewService(wallpaperService *wallpaper.Service) *Service {
 	}
 }
 
+// GlobalSession is the session key used when a single playlist drives every
+// screen, which is only the case in clone and span mode.
+const GlobalSession = "Global"
+
+const randomAllPlaylist = "Random All"
+
+// StartPlaylistCycle starts a screen's playlist from scratch, discarding any
+// state saved for it.
 func (service *Service) StartPlaylistCycle(screenName string) error {
+	return service.startPlaylistCycle(screenName, false)
+}
+
+// RestorePlaylistCycle continues a screen's playlist from the state saved
+// before the last shutdown, falling back to a fresh start when there is none.
+func (service *Service) RestorePlaylistCycle(screenName string) error {
+	return service.startPlaylistCycle(screenName, true)
+}
+
+func (service *Service) startPlaylistCycle(screenName string, restore bool) error {
 	service.mutex.Lock()
 	defer service.mutex.Unlock()
 
-	service.stopPlaylistCycleInternal(screenName)
-
 	appConfig, err := config.ReadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to read config: %w", err)
 	}
 
-	if appConfig.Playlist == "" {
-		return fmt.Errorf("no playlist configured")
+	sessionKey, err := resolveSessionKey(appConfig, screenName)
+	if err != nil {
+		return err
+	}
+
+	if group := service.runningGroupOf(sessionKey); group != nil {
+		return fmt.Errorf("screen '%s' is driven by playlist %s", sessionKey, group.ScreenName)
+	}
+
+	service.stopPlaylistCycleInternal(sessionKey)
+
+	playlistName, intervalMinutes, order, err := resolveScreenPlaylist(appConfig, sessionKey)
+	if err != nil {
+		return err
+	}
+
+	playlists, err := GetPlaylists()
+	if err != nil {
+		return fmt.Errorf("failed to get playlists: %w", err)
+	}
+
+	var selectedPlaylist *Playlist
+	for i := range playlists {
+		if playlists[i].Name == playlistName {
+			selectedPlaylist = &playlists[i]
+			break
+		}
 	}
 
 	var session *Session
-	if appConfig.Playlist == "Random All" {
-		wallpapers, err := wallpaper.GetWallpapers()
+	if selectedPlaylist == nil {
+		smartPlaylist, err := findSmartPlaylist(playlistName)
+		if err != nil {
+			return fmt.Errorf("failed to get smart playlists: %w", err)
+		}
+		if smartPlaylist == nil {
+			return fmt.Errorf("playlist '%s' not found", playlistName)
+		}
+
+		ids, err := EvaluateSmartQuery(smartPlaylist.Query)
 		if err != nil {
-			return fmt.Errorf("failed to get all wallpapers: %w", err)
+			return err
 		}
-		var ids []string
-		for id := range wallpapers {
-			ids = append(ids, id)
+		settings := smartPlaylist.Settings
+		if intervalMinutes > 0 {
+			settings.Delay = int(math.Round(intervalMinutes * 60))
 		}
+		query := smartPlaylist.Query
 		session = &Session{
-			Playlist: &Playlist{
-				Name: "Random All",
-				Settings: PlaylistSettings{
-					Delay: int(appConfig.PlaylistInterval * 60),
-				},
-			},
+			Playlist:   &Playlist{Name: smartPlaylist.Name, Items: []string{}, Settings: settings},
+			Query:      &query,
 			Wallpapers: ids,
 		}
 	} else {
-		playlists, err := GetPlaylists()
-		if err != nil {
-			return fmt.Errorf("failed to get playlists: %w", err)
-		}
-
-		var selectedPlaylist *Playlist
-		for i := range playlists {
-			if playlists[i].Name == appConfig.Playlist {
-				selectedPlaylist = &playlists[i]
-				break
-			}
+		if len(selectedPlaylist.Items) == 0 {
+			return fmt.Errorf("playlist '%s' has no wallpapers", playlistName)
 		}
 
-		if selectedPlaylist == nil {
-			return fmt.Errorf("playlist '%s' not found", appConfig.Playlist)
-		}
-
-		if len(selectedPlaylist.Items) == 0 {
-			return fmt.Errorf("playlist '%s' has no wallpapers", appConfig.Playlist)
+		// The screen's own interval takes precedence over the delay stored in
+		// the playlist, so screens sharing a playlist can run at different rates.
+		if intervalMinutes > 0 {
+			selectedPlaylist.Settings.Delay = int(math.Round(intervalMinutes * 60))
 		}
 
 		session = &Session{
 			Playlist:   selectedPlaylist,
-			Wallpapers: service.extractWallpaperIDs(selectedPlaylist.Items),
+			Wallpapers: service.resolveWallpapers(selectedPlaylist.Name, selectedPlaylist.Items),
 		}
 	}
+	session.ScreenName = sessionKey
+	if isGroupSession(sessionKey) {
+		group, err := findPlaylistGroup(appConfig, sessionKey)
+		if err != nil {
+			return err
+		}
+		if len(group.Screens) == 0 {
+			return fmt.Errorf("playlist group '%s' has no screens", group.Name)
+		}
+		session.Screens = append([]string{}, group.Screens...)
+		session.Stagger = time.Duration(group.StaggerSeconds * float64(time.Second))
+		// Member screens are driven by the group from now on.
+		for _, screenName := range session.Screens {
+			service.stopPlaylistCycleInternal(screenName)
+		}
+	}
+	if order != "" {
+		session.Playlist.Settings.Order = order
+	}
+
+	session.AspectPolicy, session.screenAspect = aspectPolicyFor(appConfig, sessionKey)
+	session.Wallpapers = session.fitScreen(session.Wallpapers)
 
 	if len(session.Wallpapers) == 0 {
 		return fmt.Errorf("no valid wallpapers found in playlist")
 	}
 
-	if err := service.applyRandomPlaylistWallpaper(appConfig, session, screenName); err != nil {
-		logger.Error("Failed to apply initial playlist wallpaper: %v", err)
+	session.Schedule = NewSchedule(session.Playlist.Settings)
+
+	// Log-on playlists exist to pick a new wallpaper on every start, so they
+	// are never restored.
+	var savedState SessionState
+	restored := false
+	if restore && session.Schedule.Mode() != ModeLogOn {
+		if state, ok := loadSessionState(sessionKey); ok && state.Playlist == playlistName {
+			session.restore(state)
+			savedState = state
+			restored = true
+		}
 	}
 
-	if session.Playlist.Settings.Delay == 0 {
-		logger.Printf("Playlist delay is 0, applied single wallpaper from playlist '%s'", appConfig.Playlist)
-		return nil
+	now := time.Now()
+	applied := false
+	if !restored || (session.Schedule.Mode() != ModeTimer && session.isDue(now)) {
+		// The session is not running yet, so its pick can be applied
+		// directly.
+		if err := service.applyNextPlaylistWallpaper(session, false); err != nil {
+			logger.Error("Failed to apply initial playlist wallpaper: %v", err)
+		} else if err := service.assignWallpapers(session.pending); err != nil {
+			logger.Error("Failed to apply initial playlist wallpaper: %v", err)
+		}
+		session.pending = nil
+		applied = true
+		now = time.Now()
 	}
 
-	interval := time.Duration(session.Playlist.Settings.Delay) * time.Second
-	if interval < 1*time.Second {
-		interval = 1 * time.Second
+	nextChange := session.nextChangeAfter(now)
+	if restored && session.Schedule.Mode() == ModeTimer && !nextChange.IsZero() {
+		nextChange = now.Add(time.Duration(savedState.RemainingSeconds * float64(time.Second)))
+	}
+	if nextChange.IsZero() {
+		if applied {
+			service.emit(EventAdvanced, session)
+		}
+		forgetSessionState(sessionKey)
+		logger.Printf("Playlist '%s' does not change on its own (mode: %s), applied single wallpaper to screen '%s'",
+			playlistName, session.Schedule.Mode(), sessionKey)
+		return nil
 	}
 
-	session.Timer = time.NewTicker(interval)
+	session.NextChange = nextChange
+	session.Timer = time.NewTimer(waitDuration(session.Schedule, nextChange, now))
 	session.StopChan = make(chan bool)
-	session.PauseChan = make(chan bool)
-	session.ResumeChan = make(chan bool)
+	session.ResumeChan = make(chan bool, 1)
 	session.UpdateChan = make(chan float64)
-	session.Paused = false
+	service.activePlaylistSessions[sessionKey] = session
 
-	service.activePlaylistSessions[screenName] = session
+	session.persist()
+	if applied {
+		service.emit(EventAdvanced, session)
+	}
 
-	go func() {
-		for {
-			select {
-			case <-session.Timer.C:
-				if !session.Paused {
-					currentConfig, err := config.ReadConfig()
-					if err != nil {
-						logger.Error("Failed to read config for playlist on screen %s: %v", screenName, err)
-						continue
-					}
-					if err := service.applyRandomPlaylistWallpaper(currentConfig, session, screenName); err != nil {
-						logger.Error("Failed to apply playlist wallpaper on screen %s: %v", screenName, err)
-					}
-				}
-			case <-session.PauseChan:
-				session.Paused = true
-			case <-session.ResumeChan:
-				session.Paused = false
-			case newIntervalMinutes := <-session.UpdateChan:
-				session.Timer.Stop()
-				newInterval := time.Duration(newIntervalMinutes * float64(time.Minute))
-				if newInterval < 1*time.Second {
-					newInterval = 1 * time.Second
-				}
-				session.Timer = time.NewTicker(newInterval)
-				logger.Printf("Updated playlist interval for screen %s to %v", screenName, newInterval)
-			case <-session.StopChan:
-				return
+	go service.runSession(session)
+
+	action := "Started"
+	if restored {
+		action = "Restored"
+	}
+	logger.Printf("%s playlist cycle for screen '%s' with playlist '%s' (%d wallpapers), mode: %s, next change: %s",
+		action, sessionKey, playlistName, len(session.Wallpapers), session.Schedule.Mode(), nextChange.Format(time.RFC3339))
+	return nil
+}
+
+// resolveSessionKey maps a requested screen to the session that drives it.
+// In clone and span mode every screen shows the same wallpaper, so all
+// requests go to the Global session; otherwise each screen has its own.
+func resolveSessionKey(appConfig config.AppConfig, screenName string) (string, error) {
+	if isGroupSession(screenName) {
+		if appConfig.CloneMode || appConfig.SpanMode {
+			return "", fmt.Errorf("playlist groups are not used in clone or span mode")
+		}
+		return screenName, nil
+	}
+	if appConfig.CloneMode || appConfig.SpanMode {
+		return GlobalSession, nil
+	}
+	if screenName == "" || screenName == GlobalSession {
+		return "", fmt.Errorf("the Global playlist is only used in clone or span mode; specify a screen")
+	}
+	return screenName, nil
+}
+
+// sessionKeyFor resolves screenName like resolveSessionKey but falls back to
+// the name itself, so stale sessions can still be addressed after the
+// display mode changed.
+func sessionKeyFor(screenName string) string {
+	if appConfig, err := config.ReadConfig(); err == nil {
+		if sessionKey, err := resolveSessionKey(appConfig, screenName); err == nil {
+			return sessionKey
+		}
+	}
+	return screenName
+}
+
+func (service *Service) runningSession(screenName string) (*Session, error) {
+	sessionKey := sessionKeyFor(screenName)
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	session, exists := service.activePlaylistSessions[sessionKey]
+	if !exists {
+		if group := service.runningGroupOf(sessionKey); group != nil {
+			return group, nil
+		}
+		return nil, fmt.Errorf("no playlist is currently running for screen %s", sessionKey)
+	}
+	return session, nil
+}
+
+// runningGroupOf returns the running group session that drives screenName.
+// The caller must hold the service mutex.
+func (service *Service) runningGroupOf(screenName string) *Session {
+	for _, session := range service.activePlaylistSessions {
+		if indexOf(session.Screens, screenName) >= 0 {
+			return session
+		}
+	}
+	return nil
+}
+
+func (service *Service) runningSessionNames() []string {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	names := make([]string, 0, len(service.activePlaylistSessions))
+	for sessionKey := range service.activePlaylistSessions {
+		names = append(names, sessionKey)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func resolveScreenPlaylist(appConfig config.AppConfig, sessionKey string) (string, float64, string, error) {
+	if sessionKey == GlobalSession {
+		if appConfig.Playlist == "" {
+			return "", 0, "", fmt.Errorf("no playlist configured")
+		}
+		return appConfig.Playlist, appConfig.PlaylistInterval, appConfig.PlaylistOrder, nil
+	}
+	if isGroupSession(sessionKey) {
+		group, err := findPlaylistGroup(appConfig, sessionKey)
+		if err != nil {
+			return "", 0, "", err
+		}
+		if group.Playlist == "" {
+			return "", 0, "", fmt.Errorf("no playlist configured for group '%s'", group.Name)
+		}
+		return group.Playlist, group.PlaylistInterval, group.PlaylistOrder, nil
+	}
+
+	for _, screen := range appConfig.Screens {
+		if screen.Name == sessionKey {
+			if screen.Playlist == "" {
+				return "", 0, "", fmt.Errorf("no playlist configured for screen '%s'", sessionKey)
 			}
+			return screen.Playlist, screen.PlaylistInterval, screen.PlaylistOrder, nil
 		}
-	}()
+	}
+	return "", 0, "", fmt.Errorf("screen '%s' not found in config", sessionKey)
+}
+
+// AssignPlaylist stores the playlist and interval a screen should cycle. An
+// empty playlist name clears the assignment.
+func (service *Service) AssignPlaylist(screenName, playlistName string, intervalMinutes float64) error {
+	service.configMutex.Lock()
+	defer service.configMutex.Unlock()
 
-	logger.Printf("Started playlist cycle for screen '%s' with playlist '%s' (%d wallpapers), interval: %v",
-		screenName, appConfig.Playlist, len(session.Wallpapers), interval)
+	appConfig, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	sessionKey, err := resolveSessionKey(appConfig, screenName)
+	if err != nil {
+		return err
+	}
+
+	if sessionKey == GlobalSession {
+		appConfig.Playlist = playlistName
+		appConfig.PlaylistInterval = intervalMinutes
+	} else if isGroupSession(sessionKey) {
+		group, err := findPlaylistGroup(appConfig, sessionKey)
+		if err != nil {
+			return err
+		}
+		group.Playlist = playlistName
+		group.PlaylistInterval = intervalMinutes
+	} else {
+		screenUpdated := false
+		for i := range appConfig.Screens {
+			if appConfig.Screens[i].Name == sessionKey {
+				appConfig.Screens[i].Playlist = playlistName
+				appConfig.Screens[i].PlaylistInterval = intervalMinutes
+				screenUpdated = true
+				break
+			}
+		}
+		if !screenUpdated {
+			if playlistName == "" {
+				return nil
+			}
+			appConfig.Screens = append(appConfig.Screens, config.ScreenConfig{
+				Name:             sessionKey,
+				Playlist:         playlistName,
+				PlaylistInterval: intervalMinutes,
+			})
+		}
+	}
+
+	if err := config.WriteConfig(appConfig); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
 	return nil
 }
 
 func (service *Service) StopPlaylistCycle(screenName string) {
 	service.mutex.Lock()
 	defer service.mutex.Unlock()
+
+	screenName = sessionKeyFor(screenName)
 	service.stopPlaylistCycleInternal(screenName)
+	forgetSessionState(screenName)
 }
 
 func (service *Service) stopPlaylistCycleInternal(screenName string) {
@@ -174,9 +384,18 @@ func (service *Service) stopPlaylistCycleInternal(screenName string) {
 		return
 	}
 
+	session.mutex.Lock()
+	session.stopped = true
 	if session.Timer != nil {
 		session.Timer.Stop()
 	}
+	staggerTimers := session.staggerTimers
+	session.staggerTimers = nil
+	session.mutex.Unlock()
+
+	for _, timer := range staggerTimers {
+		timer.Stop()
+	}
 	if session.StopChan != nil {
 		close(session.StopChan)
 	}
@@ -184,18 +403,22 @@ func (service *Service) stopPlaylistCycleInternal(screenName string) {
 	logger.Printf("Stopped playlist cycle for screen '%s'", screenName)
 }
 
-func (service *Service) PausePlaylistCycle() {
+// PausePlaylistCycle pauses every running session, recording why so the UI
+// can tell the user.
+func (service *Service) PausePlaylistCycle(reason string) {
 	service.mutex.Lock()
 	defer service.mutex.Unlock()
 
 	for _, session := range service.activePlaylistSessions {
-		if session.Timer != nil && session.PauseChan != nil && !session.Paused {
-			select {
-			case session.PauseChan <- true:
-				logger.Printf("Paused playlist cycle")
-			default:
-			}
+		if session.Timer == nil {
+			continue
+		}
+		session.mutex.Lock()
+		if !session.Paused {
+			service.setPaused(session, true, reason)
+			logger.Printf("Paused playlist cycle for screen %s", session.ScreenName)
 		}
+		session.mutex.Unlock()
 	}
 }
 
@@ -204,11 +427,22 @@ func (service *Service) ResumePlaylistCycle() {
 	defer service.mutex.Unlock()
 
 	for _, session := range service.activePlaylistSessions {
-		if session.Timer != nil && session.ResumeChan != nil && session.Paused {
+		if session.Timer == nil {
+			continue
+		}
+		session.mutex.Lock()
+		resumed := session.Paused
+		if resumed {
+			service.setPaused(session, false, "")
+			logger.Printf("Resumed playlist cycle for screen %s", session.ScreenName)
+		}
+		session.mutex.Unlock()
+
+		if resumed && session.ResumeChan != nil {
 			select {
 			case session.ResumeChan <- true:
-				logger.Printf("Resumed playlist cycle")
 			default:
+				// A catch-up is already pending.
 			}
 		}
 	}
@@ -218,17 +452,13 @@ func (service *Service) UpdatePlaylistInterval(screenName string, intervalMinute
 	service.mutex.Lock()
 	defer service.mutex.Unlock()
 
+	screenName = sessionKeyFor(screenName)
+
 	session, exists := service.activePlaylistSessions[screenName]
 	if !exists || session.Timer == nil || session.UpdateChan == nil {
 		return fmt.Errorf("no playlist is currently running for screen %s", screenName)
 	}
 
-	if session.Playlist != nil && session.Playlist.Name != "Random All" {
-		if err := UpdatePlaylistIntervalConfig(session.Playlist.Name, intervalMinutes); err != nil {
-			logger.Printf("Warning: failed to update playlist interval config: %v", err)
-		}
-	}
-
 	select {
 	case session.UpdateChan <- intervalMinutes:
 		logger.Printf("Sent interval update signal to screen %s: %f minutes", screenName, intervalMinutes)
@@ -238,94 +468,79 @@ func (service *Service) UpdatePlaylistInterval(screenName string, intervalMinute
 	}
 }
 
-func (service *Service) extractWallpaperIDs(items []string) []string {
-	var ids []string
-	regex := regexp.MustCompile(`431960[/\\](\d+)[/\\]`)
-
-	for _, item := range items {
-		matches := regex.FindStringSubmatch(item)
-		if len(matches) > 1 {
-			ids = append(ids, matches[1])
-		}
+// resolveWallpapers turns playlist items into launchable references, logging
+// the ones that cannot be played here.
+func (service *Service) resolveWallpapers(playlistName string, items []string) []string {
+	references, unresolved := ResolveItems(items)
+	for _, item := range unresolved {
+		logger.Printf("Skipping playlist '%s' item '%s': %s", playlistName, item.Item, item.Reason)
 	}
-
-	return ids
+	return references
 }
 
-func (service *Service) applyRandomPlaylistWallpaper(appConfig config.AppConfig, session *Session, screenName string) error {
-	if session.Playlist != nil && session.Playlist.Name == "Random All" {
-		wallpapers, err := wallpaper.GetWallpapers()
-		if err == nil {
-			var ids []string
-			for id := range wallpapers {
-				ids = append(ids, id)
-			}
-			if len(ids) > 0 {
-				session.Wallpapers = ids
-			}
-		}
-	}
-
-	if len(session.Wallpapers) == 0 {
-		return fmt.Errorf("no wallpapers in playlist")
+// assignWallpapers writes screens' wallpapers into a freshly read config in one
+// update, so concurrent sessions never overwrite each other's screens, and
+// applies them with a single ApplyWallpapers call.
+func (service *Service) assignWallpapers(assignments map[string]string) error {
+	service.configMutex.Lock()
+	appConfig, err := config.ReadConfig()
+	if err != nil {
+		service.configMutex.Unlock()
+		return fmt.Errorf("failed to read config: %w", err)
 	}
 
-	randomIndex := rand.Intn(len(session.Wallpapers))
-	wallpaperID := session.Wallpapers[randomIndex]
+	for screenName, wallpaperID := range assignments {
+		assignedWallpaper := wallpaperID
+		if screenName == GlobalSession {
+			appConfig.GlobalWallpaper = &assignedWallpaper
+			continue
+		}
 
-	updatedConfig := appConfig
-	if screenName == "Global" {
-		globalWallpaper := wallpaperID
-		updatedConfig.GlobalWallpaper = &globalWallpaper
-	} else {
 		screenUpdated := false
-		for i := range updatedConfig.Screens {
-			if updatedConfig.Screens[i].Name == screenName {
-				updatedConfig.Screens[i].Wallpaper = &wallpaperID
-				if session.Playlist != nil {
-					updatedConfig.Screens[i].Playlist = session.Playlist.Name
-					updatedConfig.Screens[i].PlaylistInterval = float64(session.Playlist.Settings.Delay) / 60.0
-				}
+		for i := range appConfig.Screens {
+			if appConfig.Screens[i].Name == screenName {
+				appConfig.Screens[i].Wallpaper = &assignedWallpaper
 				screenUpdated = true
 				break
 			}
 		}
 		if !screenUpdated {
+			service.configMutex.Unlock()
 			return fmt.Errorf("screen '%s' not found in config", screenName)
 		}
 	}
 
-	if err := config.WriteConfig(updatedConfig); err != nil {
+	err = config.WriteConfig(appConfig)
+	service.configMutex.Unlock()
+	if err != nil {
 		return fmt.Errorf("failed to update config: %w", err)
 	}
 
 	if err := service.wallpaperService.ApplyWallpapers(); err != nil {
 		return fmt.Errorf("failed to apply wallpapers: %w", err)
 	}
-
-	logger.Printf("Applied random playlist wallpaper '%s' to screen '%s'", wallpaperID, screenName)
 	return nil
 }
 
-func (service *Service) GetPlaylistStatus() map[string]interface{} {
+// GetPlaylistStatus reports every running session, or only the session that
+// drives screenName when one is given.
+func (service *Service) GetPlaylistStatus(screenName string) map[string]interface{} {
 	service.mutex.Lock()
 	defer service.mutex.Unlock()
 
+	if screenName != "" {
+		screenName = sessionKeyFor(screenName)
+	}
+
 	status := make(map[string]interface{})
 
-	for screenName, session := range service.activePlaylistSessions {
-		screenStatus := map[string]interface{}{
-			"active": session.Timer != nil,
-		}
-		if session.Playlist != nil {
-			screenStatus["playlist"] = session.Playlist.Name
-			screenStatus["wallpaperCount"] = len(session.Wallpapers)
+	for sessionKey, session := range service.activePlaylistSessions {
+		if screenName != "" && sessionKey != screenName {
+			continue
 		}
-		status[screenName] = screenStatus
-	}
-
-	if len(status) == 0 {
-		return map[string]interface{}{}
+		session.mutex.Lock()
+		status[sessionKey] = session.status(time.Now())
+		session.mutex.Unlock()
 	}
 
 	return status
@@ -0,0 +1,217 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+)
+
+var (
+	workshopItemPattern = regexp.MustCompile(`(?i)/` + config.WallpaperEngineAppID + `/(\d+)(?:/|$)`)
+	projectItemPattern  = regexp.MustCompile(`(?i)/wallpaper_engine/(projects/.+)$`)
+	windowsDrivePattern = regexp.MustCompile(`^([A-Za-z]):/(.*)$`)
+	workshopIDPattern   = regexp.MustCompile(`^\d+$`)
+)
+
+var (
+	// errNotInstalled marks items whose wallpaper is gone from this machine,
+	// as opposed to items that cannot be mapped to it at all.
+	errNotInstalled = errors.New("not installed")
+	// errNoProject marks folders that exist but hold no project.json.
+	errNoProject = errors.New("no project.json")
+)
+
+// UnresolvedItem is a playlist entry that does not map to a wallpaper on this
+// machine.
+type UnresolvedItem struct {
+	Item   string `json:"item"`
+	Reason string `json:"reason"`
+}
+
+// ResolveItem maps a playlist item to something the wallpaper service can
+// launch: a workshop ID for workshop wallpapers, or an absolute folder path
+// for everything else. Items may be written by Wallpaper Engine on Windows or
+// under Proton, so drive-letter paths are translated as well.
+func ResolveItem(item string) (string, error) {
+	if err := config.EnsureInitialized(); err != nil {
+		return "", err
+	}
+	return resolveItem(item)
+}
+
+func resolveItem(item string) (string, error) {
+	normalized := strings.ReplaceAll(strings.TrimSpace(item), `\`, "/")
+	if normalized == "" {
+		return "", fmt.Errorf("empty playlist item")
+	}
+	if workshopIDPattern.MatchString(normalized) {
+		return workshopReference(normalized)
+	}
+
+	if matches := workshopItemPattern.FindStringSubmatch(normalized); matches != nil {
+		return workshopReference(matches[1])
+	}
+
+	if matches := projectItemPattern.FindStringSubmatch(normalized); matches != nil {
+		if wallpaperEnginePath := config.GetWallpaperEnginePath(); wallpaperEnginePath != "" {
+			if folder, err := wallpaperFolder(filepath.Join(wallpaperEnginePath, filepath.FromSlash(matches[1]))); err == nil {
+				return folder, nil
+			}
+		}
+	}
+
+	path := normalized
+	if matches := windowsDrivePattern.FindStringSubmatch(normalized); matches != nil {
+		translated, err := translateWindowsPath(matches[1], matches[2])
+		if err != nil {
+			return "", err
+		}
+		path = translated
+	}
+
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("unrecognized playlist item path")
+	}
+
+	folder, err := wallpaperFolder(path)
+	if err != nil {
+		return "", err
+	}
+	// Workshop folders are referenced by ID so they match the installed list.
+	if workshopPath := config.GetWorkshopPath(); workshopPath != "" && filepath.Dir(folder) == filepath.Clean(workshopPath) {
+		return filepath.Base(folder), nil
+	}
+	return folder, nil
+}
+
+func workshopReference(workshopID string) (string, error) {
+	workshopPath := config.GetWorkshopPath()
+	if workshopPath == "" {
+		return "", fmt.Errorf("workshop folder is not configured")
+	}
+	if !isDirectory(filepath.Join(workshopPath, workshopID)) {
+		return "", fmt.Errorf("workshop item %s is %w", workshopID, errNotInstalled)
+	}
+	return workshopID, nil
+}
+
+// translateWindowsPath maps a Windows path to the Linux file system. Wine and
+// Proton map Z: to the root directory; other drives live in Wallpaper
+// Engine's Proton prefix.
+func translateWindowsPath(drive string, rest string) (string, error) {
+	drive = strings.ToLower(drive)
+	if drive == "z" {
+		return "/" + rest, nil
+	}
+
+	wallpaperEnginePath := config.GetWallpaperEnginePath()
+	if wallpaperEnginePath == "" {
+		return "", fmt.Errorf("cannot map drive %s: because Wallpaper Engine was not found", strings.ToUpper(drive))
+	}
+	steamapps := filepath.Dir(filepath.Dir(wallpaperEnginePath))
+	prefix := filepath.Join(steamapps, "compatdata", config.WallpaperEngineAppID, "pfx")
+	for _, root := range []string{
+		filepath.Join(prefix, "dosdevices", drive+":"),
+		filepath.Join(prefix, "drive_"+drive),
+	} {
+		if isDirectory(root) {
+			return filepath.Join(root, filepath.FromSlash(rest)), nil
+		}
+	}
+	return "", fmt.Errorf("drive %s: is not available in the Proton prefix", strings.ToUpper(drive))
+}
+
+// wallpaperFolder accepts a wallpaper folder or a file inside it, such as the
+// project.json or scene.json Wallpaper Engine stores in playlists.
+func wallpaperFolder(path string) (string, error) {
+	folder := filepath.Clean(path)
+	info, err := os.Stat(folder)
+	if err != nil {
+		// A renamed entry file still leaves the wallpaper folder usable.
+		if filepath.Ext(folder) == "" || !isDirectory(filepath.Dir(folder)) {
+			return "", fmt.Errorf("%s is %w", path, errNotInstalled)
+		}
+		folder = filepath.Dir(folder)
+	} else if !info.IsDir() {
+		folder = filepath.Dir(folder)
+	}
+	if _, err := os.Stat(filepath.Join(folder, "project.json")); err != nil {
+		return "", fmt.Errorf("%s has %w", folder, errNoProject)
+	}
+	return folder, nil
+}
+
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// ResolveItems resolves every item of a playlist, keeping the order of the
+// resolvable ones and reporting the rest.
+func ResolveItems(items []string) ([]string, []UnresolvedItem) {
+	if err := config.EnsureInitialized(); err != nil {
+		return []string{}, failAll(items, err)
+	}
+	return resolveItems(items)
+}
+
+func failAll(items []string, err error) []UnresolvedItem {
+	unresolved := make([]UnresolvedItem, 0, len(items))
+	for _, item := range items {
+		unresolved = append(unresolved, UnresolvedItem{Item: item, Reason: err.Error()})
+	}
+	return unresolved
+}
+
+func resolveItems(items []string) ([]string, []UnresolvedItem) {
+	references := []string{}
+	unresolved := []UnresolvedItem{}
+	for _, item := range items {
+		reference, err := resolveItem(item)
+		if err != nil {
+			unresolved = append(unresolved, UnresolvedItem{Item: item, Reason: err.Error()})
+			continue
+		}
+		references = append(references, reference)
+	}
+	return references, unresolved
+}
+
+// ResolveItemMap resolves several items with one initialization, mapping
+// each resolvable item to its reference.
+func ResolveItemMap(items []string) (map[string]string, error) {
+	if err := config.EnsureInitialized(); err != nil {
+		return nil, err
+	}
+	references := make(map[string]string, len(items))
+	for _, item := range items {
+		if reference, err := resolveItem(item); err == nil {
+			references[item] = reference
+		}
+	}
+	return references, nil
+}
+
+// UnresolvedItems reports, per playlist name, the items that cannot be played.
+// Playlists whose items all resolve are left out.
+func UnresolvedItems(playlists []Playlist) map[string][]UnresolvedItem {
+	result := make(map[string][]UnresolvedItem)
+	initErr := config.EnsureInitialized()
+	for _, playlist := range playlists {
+		var unresolved []UnresolvedItem
+		if initErr != nil {
+			unresolved = failAll(playlist.Items, initErr)
+		} else {
+			_, unresolved = resolveItems(playlist.Items)
+		}
+		if len(unresolved) > 0 {
+			result[playlist.Name] = unresolved
+		}
+	}
+	return result
+}
@@ -0,0 +1,195 @@
+package playlist
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ModeTimer     = "timer"
+	ModeDaytime   = "daytime"
+	ModeDayOfWeek = "dayofweek"
+	ModeLogOn     = "logon"
+)
+
+// resyncInterval bounds how long a clock-based schedule sleeps. Timers run on
+// the monotonic clock, which stops during suspend, so long sleeps would wake
+// up late after the machine resumes.
+const resyncInterval = 5 * time.Minute
+
+// Schedule decides when a playlist changes wallpaper and, for clock-based
+// modes, which item belongs to a given wall-clock time.
+type Schedule interface {
+	// Mode returns the normalized playlist mode.
+	Mode() string
+	// ItemAt returns the index of the item that should be active at now, or
+	// -1 when the playlist order decides instead.
+	ItemAt(now time.Time, count int) int
+	// NextChange returns the next transition after now, or the zero time when
+	// the playlist never changes on its own.
+	NextChange(now time.Time, count int) time.Time
+}
+
+func NormalizeMode(mode string) string {
+	switch strings.ToLower(strings.ReplaceAll(strings.TrimSpace(mode), "_", "")) {
+	case ModeDaytime, "clock", "timeofday":
+		return ModeDaytime
+	case ModeDayOfWeek, "weekday", "day":
+		return ModeDayOfWeek
+	case ModeLogOn, "login", "startup":
+		return ModeLogOn
+	}
+	return ModeTimer
+}
+
+func NewSchedule(settings PlaylistSettings) Schedule {
+	switch NormalizeMode(settings.Mode) {
+	case ModeDaytime:
+		return daytimeSchedule{start: parseClock(settings.Clock)}
+	case ModeDayOfWeek:
+		return dayOfWeekSchedule{}
+	case ModeLogOn:
+		return logOnSchedule{}
+	}
+	return timerSchedule{delay: time.Duration(settings.Delay) * time.Second}
+}
+
+// timerSchedule changes wallpaper every delay, letting the order pick items.
+type timerSchedule struct {
+	delay time.Duration
+}
+
+func (schedule timerSchedule) Mode() string { return ModeTimer }
+
+func (schedule timerSchedule) ItemAt(now time.Time, count int) int { return -1 }
+
+func (schedule timerSchedule) NextChange(now time.Time, count int) time.Time {
+	if schedule.delay <= 0 {
+		return time.Time{}
+	}
+	if schedule.delay < time.Second {
+		return now.Add(time.Second)
+	}
+	return now.Add(schedule.delay)
+}
+
+// daytimeSchedule splits the day into equal slots, one per item, starting at
+// the playlist's clock time.
+type daytimeSchedule struct {
+	start time.Duration
+}
+
+func (schedule daytimeSchedule) Mode() string { return ModeDaytime }
+
+func (schedule daytimeSchedule) slot(count int) time.Duration {
+	return 24 * time.Hour / time.Duration(count)
+}
+
+func (schedule daytimeSchedule) dayStart(now time.Time) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := midnight.Add(schedule.start)
+	if start.After(now) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+func (schedule daytimeSchedule) ItemAt(now time.Time, count int) int {
+	if count == 0 {
+		return -1
+	}
+	index := int(now.Sub(schedule.dayStart(now)) / schedule.slot(count))
+	if index >= count {
+		index = count - 1
+	}
+	return index
+}
+
+func (schedule daytimeSchedule) NextChange(now time.Time, count int) time.Time {
+	if count == 0 {
+		return time.Time{}
+	}
+	start := schedule.dayStart(now)
+	next := start.Add(time.Duration(schedule.ItemAt(now, count)+1) * schedule.slot(count))
+	if tomorrow := start.AddDate(0, 0, 1); !next.Before(tomorrow) {
+		next = tomorrow
+	}
+	return next
+}
+
+// dayOfWeekSchedule shows one item per weekday, Monday first, wrapping when
+// the playlist has fewer than seven items.
+type dayOfWeekSchedule struct{}
+
+func (schedule dayOfWeekSchedule) Mode() string { return ModeDayOfWeek }
+
+func (schedule dayOfWeekSchedule) ItemAt(now time.Time, count int) int {
+	if count == 0 {
+		return -1
+	}
+	weekday := (int(now.Weekday()) + 6) % 7
+	return weekday % count
+}
+
+func (schedule dayOfWeekSchedule) NextChange(now time.Time, count int) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+}
+
+// logOnSchedule picks a new wallpaper when the session starts and keeps it.
+type logOnSchedule struct{}
+
+func (schedule logOnSchedule) Mode() string { return ModeLogOn }
+
+func (schedule logOnSchedule) ItemAt(now time.Time, count int) int { return -1 }
+
+func (schedule logOnSchedule) NextChange(now time.Time, count int) time.Time { return time.Time{} }
+
+// parseClock reads the playlist clock as "HH:MM", "HH:MM:SS" or a number of
+// seconds after midnight. Invalid values start the day at midnight.
+func parseClock(clock string) time.Duration {
+	clock = strings.TrimSpace(clock)
+	if clock == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseFloat(clock, 64); err == nil {
+		return normalizeClock(time.Duration(seconds * float64(time.Second)))
+	}
+
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0
+	}
+	var total time.Duration
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	for i, part := range parts {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || value < 0 {
+			return 0
+		}
+		total += time.Duration(value) * units[i]
+	}
+	return normalizeClock(total)
+}
+
+func normalizeClock(offset time.Duration) time.Duration {
+	offset %= 24 * time.Hour
+	if offset < 0 {
+		offset += 24 * time.Hour
+	}
+	return offset
+}
+
+// waitDuration converts the next transition into a timer duration, waking up
+// early for clock-based schedules so they resync after a suspend.
+func waitDuration(schedule Schedule, next time.Time, now time.Time) time.Duration {
+	wait := next.Sub(now)
+	if schedule.Mode() != ModeTimer && wait > resyncInterval {
+		wait = resyncInterval
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
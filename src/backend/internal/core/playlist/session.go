@@ -0,0 +1,419 @@
+package playlist
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"linux-wallpaperengine-gui/src/backend/internal/core/wallpaper"
+	"linux-wallpaperengine-gui/src/backend/internal/logger"
+)
+
+// maxHistory bounds how many previously shown wallpapers a session remembers.
+const maxHistory = 50
+
+type Session struct {
+	ScreenName string
+	Timer      *time.Timer
+	Schedule   Schedule
+	NextChange time.Time
+	StopChan   chan bool
+	// ResumeChan asks the session to catch up with its clock after a
+	// resume. It holds one signal, so a resume is never lost while the
+	// session is busy.
+	ResumeChan chan bool
+	UpdateChan chan float64
+	Paused     bool
+	// PausedReason says why the session is paused, e.g. PauseReasonFullscreen.
+	PausedReason string
+	Playlist     *Playlist
+	// Query is set for smart playlists, whose wallpapers are re-evaluated on
+	// every advance.
+	Query      *SmartQuery
+	Wallpapers []string
+	Current    string
+	Position   int
+	ShuffleBag []string
+
+	// History holds previously shown wallpapers, oldest first. Forward holds
+	// the wallpapers stepped back over with "previous", most recent last, and
+	// Queue the wallpapers the user asked to show next.
+	History []string
+	Forward []string
+	Queue   []string
+	// HeldUntil keeps a skipped-to wallpaper on screen until the next clock
+	// boundary instead of snapping back to the scheduled item.
+	HeldUntil time.Time
+
+	// Screens lists the members of a playlist group, which all advance on
+	// this session's clock; Assignments holds the item each one shows.
+	Screens       []string
+	Stagger       time.Duration
+	Assignments   map[string]string
+	staggerTimers []*time.Timer
+	// stopped is set once the session is stopped, so timers that already
+	// fired neither change wallpapers nor save state for it.
+	stopped bool
+
+	// AspectPolicy decides how the screen's shape affects which wallpapers
+	// play; aspectFit holds the wallpapers that fit it.
+	AspectPolicy string
+	screenAspect float64
+	aspectFit    map[string]bool
+
+	// missing holds the wallpapers found missing at the last pick, so each
+	// is only reported once.
+	missing map[string]bool
+
+	// pending holds the screen assignments of the last pick. They are
+	// applied by advance after mutex is released, so a wallpaper switch
+	// never blocks pausing or status requests; advanceMutex keeps advances
+	// and their switches in order.
+	pending      map[string]string
+	advanceMutex sync.Mutex
+
+	mutex sync.Mutex
+}
+
+func (service *Service) runSession(session *Session) {
+	for {
+		select {
+		case <-session.Timer.C:
+			err := service.advance(session, func() bool {
+				now := time.Now()
+				due := (!session.Paused || session.Playlist.Settings.UpdateOnPause) && session.isDue(now)
+				if due {
+					if err := service.applyNextPlaylistWallpaper(session, false); err != nil {
+						logger.Error("Failed to apply playlist wallpaper on screen %s: %v", session.ScreenName, err)
+					}
+				}
+				session.scheduleNext(time.Now())
+				return due
+			})
+			if err != nil {
+				logger.Error("Failed to apply playlist wallpaper on screen %s: %v", session.ScreenName, err)
+			}
+		case <-session.ResumeChan:
+			// Clock-based playlists may have crossed a boundary while paused.
+			err := service.advance(session, func() bool {
+				if session.Paused || session.Schedule.Mode() == ModeTimer || !session.isDue(time.Now()) {
+					return false
+				}
+				if err := service.applyNextPlaylistWallpaper(session, false); err != nil {
+					logger.Error("Failed to apply playlist wallpaper on screen %s: %v", session.ScreenName, err)
+				}
+				session.scheduleNext(time.Now())
+				return true
+			})
+			if err != nil {
+				logger.Error("Failed to apply playlist wallpaper on screen %s: %v", session.ScreenName, err)
+			}
+		case newIntervalMinutes := <-session.UpdateChan:
+			session.mutex.Lock()
+			if session.Schedule.Mode() != ModeTimer {
+				logger.Printf("Ignoring interval update for screen %s: playlist mode is %s", session.ScreenName, session.Schedule.Mode())
+				session.mutex.Unlock()
+				continue
+			}
+			newInterval := time.Duration(newIntervalMinutes * float64(time.Minute))
+			if newInterval < 1*time.Second {
+				newInterval = 1 * time.Second
+			}
+			session.Playlist.Settings.Delay = int(newInterval / time.Second)
+			session.Schedule = NewSchedule(session.Playlist.Settings)
+			session.scheduleNext(time.Now())
+			session.mutex.Unlock()
+			logger.Printf("Updated playlist interval for screen %s to %v", session.ScreenName, newInterval)
+		case <-session.StopChan:
+			return
+		}
+	}
+}
+
+// advance runs pick under the session mutex, then applies the screen
+// assignments it made once the mutex is released. When pick reports that the
+// session advanced, EventAdvanced follows the switch. Sessions stopped in the
+// meantime are left alone.
+func (service *Service) advance(session *Session, pick func() bool) error {
+	session.advanceMutex.Lock()
+	defer session.advanceMutex.Unlock()
+
+	session.mutex.Lock()
+	if session.stopped {
+		session.mutex.Unlock()
+		return nil
+	}
+	advanced := pick()
+	assignments := session.pending
+	session.pending = nil
+	session.mutex.Unlock()
+
+	if len(assignments) > 0 {
+		if err := service.assignWallpapers(assignments); err != nil {
+			return err
+		}
+	}
+	if advanced {
+		session.mutex.Lock()
+		service.emit(EventAdvanced, session)
+		session.mutex.Unlock()
+	}
+	return nil
+}
+
+// setPaused records a pause or resume and tells the UI. The caller must hold
+// the session mutex.
+func (service *Service) setPaused(session *Session, paused bool, reason string) {
+	session.Paused = paused
+	session.PausedReason = reason
+	session.persist()
+	if paused {
+		service.emit(EventPaused, session)
+	} else {
+		service.emit(EventResumed, session)
+	}
+}
+
+// isDue reports whether the wallpaper should change now. Timer playlists are
+// due whenever their timer fires; clock-based ones only when the item for the
+// current time differs from the one on screen.
+func (session *Session) isDue(now time.Time) bool {
+	index := session.Schedule.ItemAt(now, len(session.Wallpapers))
+	if index < 0 {
+		return true
+	}
+	if now.Before(session.HeldUntil) {
+		return false
+	}
+	return session.Current != session.Wallpapers[index]
+}
+
+// nextChangeAfter returns when the wallpaper shown at now should change. With
+// video sequence enabled, timer playlists let a Video item play to its end and
+// fall back to the delay for anything else.
+func (session *Session) nextChangeAfter(now time.Time) time.Time {
+	if session.Playlist != nil && session.Playlist.Settings.VideoSequence &&
+		session.Schedule.Mode() == ModeTimer && session.Current != "" {
+		duration, err := wallpaper.GetWallpaperVideoDuration(session.Current)
+		if err != nil {
+			logger.Printf("Could not read video duration of '%s', using playlist delay: %v", session.Current, err)
+		} else if duration > 0 {
+			if duration < time.Second {
+				duration = time.Second
+			}
+			return now.Add(duration)
+		}
+	}
+	return session.Schedule.NextChange(now, len(session.Wallpapers))
+}
+
+func (session *Session) scheduleNext(now time.Time) {
+	next := session.nextChangeAfter(now)
+	session.NextChange = next
+	if session.Timer == nil {
+		return
+	}
+	if next.IsZero() {
+		session.Timer.Stop()
+		return
+	}
+	session.Timer.Reset(waitDuration(session.Schedule, next, now))
+	session.persist()
+}
+
+// applyNextPlaylistWallpaper shows the item that follows the current one:
+// wallpapers stepped back over come first, then queued ones, then whatever
+// the schedule and order pick. A skip ignores the clock so that clock-based
+// playlists can still be advanced by hand.
+func (service *Service) applyNextPlaylistWallpaper(session *Session, skip bool) error {
+	// Smart playlists follow the catalog, so wallpapers installed or changed
+	// since the last advance are picked up.
+	if session.Query != nil {
+		if ids, err := EvaluateSmartQuery(*session.Query); err != nil {
+			logger.Printf("Failed to evaluate smart playlist on screen %s: %v", session.ScreenName, err)
+		} else if len(ids) > 0 {
+			session.Wallpapers = session.fitScreen(ids)
+		}
+	}
+
+	if len(session.Wallpapers) == 0 {
+		return fmt.Errorf("no wallpapers in playlist")
+	}
+
+	// The pick is made among the installed wallpapers only; the full list is
+	// put back afterwards.
+	all := session.Wallpapers
+	session.Wallpapers = session.playableWallpapers()
+	defer func() {
+		session.Wallpapers = all
+		if index := indexOf(all, session.Current); index >= 0 {
+			session.Position = index
+		}
+	}()
+	if len(session.Wallpapers) == 0 {
+		return fmt.Errorf("none of the %d wallpapers in the playlist is installed", len(all))
+	}
+
+	now := time.Now()
+	wallpaperID := session.takePlayable(&session.Forward, true)
+	if wallpaperID == "" {
+		wallpaperID = session.takePlayable(&session.Queue, false)
+	}
+
+	if wallpaperID != "" {
+		session.hold(now)
+		if index := indexOf(session.Wallpapers, wallpaperID); index >= 0 {
+			session.Position = index
+		}
+	} else if index := session.Schedule.ItemAt(now, len(session.Wallpapers)); index >= 0 && !skip {
+		wallpaperID = session.Wallpapers[index]
+		session.Position = index
+		session.HeldUntil = time.Time{}
+	} else {
+		// nextWallpaper updates Current itself, so remember the outgoing item.
+		previous := session.Current
+		wallpaperID = session.nextWallpaper()
+		session.Current = previous
+		session.hold(now)
+	}
+
+	return service.showWallpaper(session, wallpaperID, true)
+}
+
+// applyPreviousPlaylistWallpaper steps back to the last wallpaper in the
+// session's history.
+func (service *Service) applyPreviousPlaylistWallpaper(session *Session) error {
+	wallpaperID := session.takePlayable(&session.History, true)
+	if wallpaperID == "" {
+		return fmt.Errorf("no previous wallpaper for screen %s", session.ScreenName)
+	}
+	if session.Current != "" {
+		session.Forward = append(session.Forward, session.Current)
+	}
+	if index := indexOf(session.Wallpapers, wallpaperID); index >= 0 {
+		session.Position = index
+	}
+	session.hold(time.Now())
+
+	return service.showWallpaper(session, wallpaperID, false)
+}
+
+// hold keeps a hand-picked wallpaper on a clock-based playlist until the next
+// scheduled boundary.
+func (session *Session) hold(now time.Time) {
+	if session.Schedule.Mode() == ModeTimer {
+		return
+	}
+	session.HeldUntil = session.Schedule.NextChange(now, len(session.Wallpapers))
+}
+
+func (service *Service) showWallpaper(session *Session, wallpaperID string, recordHistory bool) error {
+	if recordHistory && session.Current != "" && session.Current != wallpaperID {
+		session.History = append(session.History, session.Current)
+		if len(session.History) > maxHistory {
+			session.History = session.History[len(session.History)-maxHistory:]
+		}
+	}
+	session.Current = wallpaperID
+
+	if len(session.Screens) > 0 {
+		return service.showGroupWallpapers(session, wallpaperID)
+	}
+	session.pending = map[string]string{session.ScreenName: wallpaperID}
+
+	logger.Printf("Showing playlist wallpaper '%s' (%s, %s) to screen '%s'",
+		wallpaperID, session.Schedule.Mode(), session.order(), session.ScreenName)
+	return nil
+}
+
+func (service *Service) NextWallpaper(screenName string) error {
+	session, err := service.runningSession(screenName)
+	if err != nil {
+		return err
+	}
+
+	var pickErr error
+	err = service.advance(session, func() bool {
+		if pickErr = service.applyNextPlaylistWallpaper(session, true); pickErr != nil {
+			return false
+		}
+		session.scheduleNext(time.Now())
+		return true
+	})
+	if pickErr != nil {
+		return pickErr
+	}
+	return err
+}
+
+func (service *Service) PreviousWallpaper(screenName string) error {
+	session, err := service.runningSession(screenName)
+	if err != nil {
+		return err
+	}
+
+	var pickErr error
+	err = service.advance(session, func() bool {
+		if pickErr = service.applyPreviousPlaylistWallpaper(session); pickErr != nil {
+			return false
+		}
+		session.scheduleNext(time.Now())
+		return true
+	})
+	if pickErr != nil {
+		return pickErr
+	}
+	return err
+}
+
+// QueueWallpaper schedules wallpaperID to be shown at the next change. An
+// empty ID leaves the queue untouched, which lets callers read it.
+func (service *Service) QueueWallpaper(screenName string, wallpaperID string) (map[string]interface{}, error) {
+	session, err := service.runningSession(screenName)
+	if err != nil {
+		return nil, err
+	}
+
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	if wallpaperID != "" {
+		session.Queue = append(session.Queue, wallpaperID)
+	}
+
+	return map[string]interface{}{
+		"current": session.Current,
+		"history": append([]string{}, session.History...),
+		"queue":   append([]string{}, session.Queue...),
+	}, nil
+}
+
+func (service *Service) ClearQueue(screenName string) error {
+	session, err := service.runningSession(screenName)
+	if err != nil {
+		return err
+	}
+
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	session.Queue = nil
+	return nil
+}
+
+// NextWallpaperAll and PreviousWallpaperAll skip every running session, for
+// global shortcuts such as the tray menu.
+func (service *Service) NextWallpaperAll() {
+	for _, screenName := range service.runningSessionNames() {
+		if err := service.NextWallpaper(screenName); err != nil {
+			logger.Printf("Failed to skip to next wallpaper on screen %s: %v", screenName, err)
+		}
+	}
+}
+
+func (service *Service) PreviousWallpaperAll() {
+	for _, screenName := range service.runningSessionNames() {
+		if err := service.PreviousWallpaper(screenName); err != nil {
+			logger.Printf("Failed to go back to previous wallpaper on screen %s: %v", screenName, err)
+		}
+	}
+}
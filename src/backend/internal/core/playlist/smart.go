@@ -0,0 +1,281 @@
+package playlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+	"linux-wallpaperengine-gui/src/backend/internal/core/rating"
+	"linux-wallpaperengine-gui/src/backend/internal/core/wallpaper"
+)
+
+// SmartQuery selects wallpapers from the installed catalog. Empty fields match
+// everything, so the zero query selects every installed wallpaper.
+type SmartQuery struct {
+	// Tags matches wallpapers carrying any of the tags, ExcludeTags drops
+	// wallpapers carrying any of them.
+	Tags           []string `json:"tags,omitempty"`
+	ExcludeTags    []string `json:"excludeTags,omitempty"`
+	Types          []string `json:"types,omitempty"`
+	ContentRatings []string `json:"contentRatings,omitempty"`
+	// InstalledAfter and InstalledBefore are Unix timestamps.
+	// InstalledWithinDays is relative to the moment the query runs.
+	InstalledAfter      int64 `json:"installedAfter,omitempty"`
+	InstalledBefore     int64 `json:"installedBefore,omitempty"`
+	InstalledWithinDays int   `json:"installedWithinDays,omitempty"`
+	FavoritesOnly       bool  `json:"favoritesOnly,omitempty"`
+	MinRating           int   `json:"minRating,omitempty"`
+}
+
+// SmartPlaylist is a playlist whose items are the result of a query, evaluated
+// again every time the session advances.
+type SmartPlaylist struct {
+	Name     string           `json:"name"`
+	Query    SmartQuery       `json:"query"`
+	Settings PlaylistSettings `json:"settings"`
+}
+
+type smartPlaylistFile struct {
+	Playlists []SmartPlaylist `json:"playlists"`
+}
+
+var smartPlaylistMutex sync.Mutex
+
+func smartPlaylistPath() string {
+	return filepath.Join(filepath.Dir(config.ConfigPath), "smart-playlists.json")
+}
+
+func readSmartPlaylists() ([]SmartPlaylist, error) {
+	data, err := os.ReadFile(smartPlaylistPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []SmartPlaylist{}, nil
+		}
+		return nil, fmt.Errorf("failed to read smart-playlists.json: %w", err)
+	}
+
+	var file smartPlaylistFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse smart-playlists.json: %w", err)
+	}
+	if file.Playlists == nil {
+		file.Playlists = []SmartPlaylist{}
+	}
+	return file.Playlists, nil
+}
+
+func writeSmartPlaylists(playlists []SmartPlaylist) error {
+	path := smartPlaylistPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(smartPlaylistFile{Playlists: playlists}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal smart playlists: %w", err)
+	}
+
+	temporaryPath := path + ".tmp"
+	if err := os.WriteFile(temporaryPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, path)
+}
+
+func GetSmartPlaylists() ([]SmartPlaylist, error) {
+	smartPlaylistMutex.Lock()
+	defer smartPlaylistMutex.Unlock()
+	return readSmartPlaylists()
+}
+
+// SaveSmartPlaylist creates or replaces the smart playlist with the same name.
+// Names are shared with regular playlists, so they must not collide.
+func SaveSmartPlaylist(smartPlaylist SmartPlaylist) error {
+	smartPlaylist.Name = strings.TrimSpace(smartPlaylist.Name)
+	if smartPlaylist.Name == "" {
+		return fmt.Errorf("smart playlist name is required")
+	}
+	if smartPlaylist.Name == randomAllPlaylist {
+		return fmt.Errorf("'%s' is a built-in playlist", randomAllPlaylist)
+	}
+	if smartPlaylist.Query.MinRating < 0 || smartPlaylist.Query.MinRating > rating.MaxRating {
+		return fmt.Errorf("minRating must be between 0 and %d", rating.MaxRating)
+	}
+
+	// Regular playlists are only written under smartPlaylistMutex, so none
+	// can take the name between this check and the write.
+	smartPlaylistMutex.Lock()
+	defer smartPlaylistMutex.Unlock()
+
+	playlists, err := GetPlaylists()
+	if err != nil {
+		return err
+	}
+	for _, playlist := range playlists {
+		if playlist.Name == smartPlaylist.Name {
+			return fmt.Errorf("a playlist named '%s' already exists", smartPlaylist.Name)
+		}
+	}
+
+	smartPlaylists, err := readSmartPlaylists()
+	if err != nil {
+		return err
+	}
+	for i := range smartPlaylists {
+		if smartPlaylists[i].Name == smartPlaylist.Name {
+			smartPlaylists[i] = smartPlaylist
+			return writeSmartPlaylists(smartPlaylists)
+		}
+	}
+	return writeSmartPlaylists(append(smartPlaylists, smartPlaylist))
+}
+
+func DeleteSmartPlaylist(name string) error {
+	smartPlaylistMutex.Lock()
+	defer smartPlaylistMutex.Unlock()
+
+	smartPlaylists, err := readSmartPlaylists()
+	if err != nil {
+		return err
+	}
+	remaining := []SmartPlaylist{}
+	for _, smartPlaylist := range smartPlaylists {
+		if smartPlaylist.Name != name {
+			remaining = append(remaining, smartPlaylist)
+		}
+	}
+	if len(remaining) == len(smartPlaylists) {
+		return fmt.Errorf("smart playlist '%s' not found", name)
+	}
+	return writeSmartPlaylists(remaining)
+}
+
+// checkPlaylistName rejects a regular playlist name that would shadow "Random
+// All" or a smart playlist. The caller must hold smartPlaylistMutex, as
+// UpdatePlaylists does.
+func checkPlaylistName(name string) error {
+	if name == randomAllPlaylist {
+		return fmt.Errorf("'%s' is a built-in playlist", randomAllPlaylist)
+	}
+	smartPlaylists, err := readSmartPlaylists()
+	if err != nil {
+		return err
+	}
+	for _, smartPlaylist := range smartPlaylists {
+		if smartPlaylist.Name == name {
+			return fmt.Errorf("a smart playlist named '%s' already exists", name)
+		}
+	}
+	return nil
+}
+
+// findSmartPlaylist looks up a smart playlist by name. "Random All" is the
+// built-in smart playlist with an empty query.
+func findSmartPlaylist(name string) (*SmartPlaylist, error) {
+	if name == randomAllPlaylist {
+		return &SmartPlaylist{
+			Name:     randomAllPlaylist,
+			Settings: PlaylistSettings{Order: OrderRandom},
+		}, nil
+	}
+
+	smartPlaylists, err := GetSmartPlaylists()
+	if err != nil {
+		return nil, err
+	}
+	for i := range smartPlaylists {
+		if smartPlaylists[i].Name == name {
+			return &smartPlaylists[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// EvaluateSmartQuery returns the installed wallpapers matching the query,
+// sorted by folder name so sequential playback is reproducible.
+func EvaluateSmartQuery(query SmartQuery) ([]string, error) {
+	wallpapers, err := wallpaper.GetWallpapers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all wallpapers: %w", err)
+	}
+
+	var ratings map[string]rating.Rating
+	if query.FavoritesOnly || query.MinRating > 0 {
+		if ratings, err = rating.GetRatings(); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	ids := []string{}
+	for id, data := range wallpapers {
+		if query.matches(id, data, ratings, now) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (query SmartQuery) matches(id string, data wallpaper.WallpaperData, ratings map[string]rating.Rating, now time.Time) bool {
+	project := data.ProjectData
+	if project == nil {
+		project = &wallpaper.WallpaperProjectData{}
+	}
+
+	if len(query.Types) > 0 && !containsFold(query.Types, project.Type) {
+		return false
+	}
+	if len(query.ContentRatings) > 0 && !containsFold(query.ContentRatings, project.ContentRating) {
+		return false
+	}
+	if len(query.Tags) > 0 && !anyFold(query.Tags, project.Tags) {
+		return false
+	}
+	if len(query.ExcludeTags) > 0 && anyFold(query.ExcludeTags, project.Tags) {
+		return false
+	}
+
+	if query.InstalledAfter > 0 && data.InstallDate < query.InstalledAfter {
+		return false
+	}
+	if query.InstalledBefore > 0 && data.InstallDate > query.InstalledBefore {
+		return false
+	}
+	if query.InstalledWithinDays > 0 && data.InstallDate < now.AddDate(0, 0, -query.InstalledWithinDays).Unix() {
+		return false
+	}
+
+	wallpaperRating := ratings[rating.Key(id)]
+	if query.FavoritesOnly && !wallpaperRating.Favorite {
+		return false
+	}
+	if query.MinRating > 0 && wallpaperRating.Stars < query.MinRating {
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyFold(wanted []string, values []string) bool {
+	for _, value := range values {
+		if containsFold(wanted, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package playlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+	"linux-wallpaperengine-gui/src/backend/internal/logger"
+)
+
+// SessionState is the part of a running session that survives a backend
+// restart.
+type SessionState struct {
+	Playlist         string   `json:"playlist"`
+	Current          string   `json:"current"`
+	Position         int      `json:"position"`
+	ShuffleBag       []string `json:"shuffleBag,omitempty"`
+	History          []string `json:"history,omitempty"`
+	RemainingSeconds float64  `json:"remainingSeconds"`
+	Paused           bool     `json:"paused"`
+	PausedReason     string   `json:"pausedReason,omitempty"`
+	SavedAt          int64    `json:"savedAt"`
+}
+
+type stateFile struct {
+	Sessions map[string]SessionState `json:"sessions"`
+}
+
+var stateMutex sync.Mutex
+
+func statePath() string {
+	return filepath.Join(config.StateDir, "playlist-state.json")
+}
+
+func readStateFile() stateFile {
+	state := stateFile{Sessions: make(map[string]SessionState)}
+	data, err := os.ReadFile(statePath())
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		logger.Printf("Ignoring unreadable playlist state file: %v", err)
+		return stateFile{Sessions: make(map[string]SessionState)}
+	}
+	if state.Sessions == nil {
+		state.Sessions = make(map[string]SessionState)
+	}
+	return state
+}
+
+func writeStateFile(state stateFile) error {
+	if err := os.MkdirAll(config.StateDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temporaryPath := statePath() + ".tmp"
+	if err := os.WriteFile(temporaryPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, statePath())
+}
+
+func loadSessionState(sessionKey string) (SessionState, bool) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	state, ok := readStateFile().Sessions[sessionKey]
+	return state, ok
+}
+
+// saveSessionStates merges the given sessions into the state file. A nil
+// entry removes the session.
+func saveSessionStates(states map[string]*SessionState) error {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	file := readStateFile()
+	for sessionKey, state := range states {
+		if state == nil {
+			delete(file.Sessions, sessionKey)
+		} else {
+			file.Sessions[sessionKey] = *state
+		}
+	}
+	if err := writeStateFile(file); err != nil {
+		return fmt.Errorf("failed to write playlist state: %w", err)
+	}
+	return nil
+}
+
+// snapshot captures the session for persistence. The caller must hold the
+// session mutex.
+func (session *Session) snapshot(now time.Time) *SessionState {
+	state := &SessionState{
+		Current:      session.Current,
+		Position:     session.Position,
+		ShuffleBag:   append([]string{}, session.ShuffleBag...),
+		History:      append([]string{}, session.History...),
+		Paused:       session.Paused,
+		PausedReason: session.PausedReason,
+		SavedAt:      now.Unix(),
+	}
+	if session.Playlist != nil {
+		state.Playlist = session.Playlist.Name
+	}
+	if !session.NextChange.IsZero() {
+		state.RemainingSeconds = session.NextChange.Sub(now).Seconds()
+		if state.RemainingSeconds < 0 {
+			state.RemainingSeconds = 0
+		}
+	}
+	return state
+}
+
+func (session *Session) restore(state SessionState) {
+	session.Current = state.Current
+	session.Position = state.Position
+	session.ShuffleBag = retainAvailable(state.ShuffleBag, session.Wallpapers)
+	session.History = state.History
+	session.Paused = state.Paused
+	session.PausedReason = state.PausedReason
+}
+
+// persist saves the session's state. The caller must hold the session mutex.
+func (session *Session) persist() {
+	states := map[string]*SessionState{session.ScreenName: session.snapshot(time.Now())}
+	if err := saveSessionStates(states); err != nil {
+		logger.Printf("Failed to persist playlist state for screen %s: %v", session.ScreenName, err)
+	}
+}
+
+func forgetSessionState(sessionKey string) {
+	if err := saveSessionStates(map[string]*SessionState{sessionKey: nil}); err != nil {
+		logger.Printf("Failed to clear playlist state for screen %s: %v", sessionKey, err)
+	}
+}
+
+// SaveState writes every running session to the state file, capturing the
+// time left until each one's next change. It is called on shutdown.
+func (service *Service) SaveState() {
+	service.mutex.Lock()
+	sessions := make([]*Session, 0, len(service.activePlaylistSessions))
+	for _, session := range service.activePlaylistSessions {
+		sessions = append(sessions, session)
+	}
+	service.mutex.Unlock()
+
+	now := time.Now()
+	states := make(map[string]*SessionState, len(sessions))
+	for _, session := range sessions {
+		session.mutex.Lock()
+		states[session.ScreenName] = session.snapshot(now)
+		session.mutex.Unlock()
+	}
+
+	if len(states) == 0 {
+		return
+	}
+	if err := saveSessionStates(states); err != nil {
+		logger.Printf("Failed to save playlist state: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package playlist
+
+import (
+	"time"
+)
+
+// PauseReasonFullscreen marks sessions paused because an application went
+// fullscreen.
+const PauseReasonFullscreen = "fullscreen"
+
+const (
+	EventAdvanced = "playlist-advanced"
+	EventPaused   = "playlist-paused"
+	EventResumed  = "playlist-resumed"
+)
+
+// EventHandler receives playlist events together with the status of the
+// session they concern.
+type EventHandler func(event string, payload interface{})
+
+// SetEventHandler registers the function that receives playlist events. It
+// must be called before any playlist starts.
+func (service *Service) SetEventHandler(handler EventHandler) {
+	service.eventHandler = handler
+}
+
+// emit reports a session change. The caller must hold the session mutex.
+func (service *Service) emit(event string, session *Session) {
+	if service.eventHandler == nil {
+		return
+	}
+	payload := session.status(time.Now())
+	payload["screenName"] = session.ScreenName
+	service.eventHandler(event, payload)
+}
+
+// status describes the session for the UI. The caller must hold the session
+// mutex.
+func (session *Session) status(now time.Time) map[string]interface{} {
+	status := map[string]interface{}{
+		"active":       session.Timer != nil,
+		"current":      session.Current,
+		"position":     session.Position,
+		"paused":       session.Paused,
+		"pausedReason": session.PausedReason,
+		"queue":        append([]string{}, session.Queue...),
+		"aspectPolicy": normalizeAspectPolicy(session.AspectPolicy),
+		"nextChange":   nil,
+	}
+	if session.Playlist != nil {
+		status["playlist"] = session.Playlist.Name
+		status["wallpaperCount"] = len(session.Wallpapers)
+		status["order"] = session.order()
+	}
+	if len(session.Screens) > 0 {
+		assignments := make(map[string]string, len(session.Assignments))
+		for screenName, wallpaperID := range session.Assignments {
+			assignments[screenName] = wallpaperID
+		}
+		status["screens"] = append([]string{}, session.Screens...)
+		status["assignments"] = assignments
+	}
+	if session.Schedule != nil {
+		status["mode"] = session.Schedule.Mode()
+	}
+	if !session.NextChange.IsZero() {
+		status["nextChange"] = session.NextChange.Format(time.RFC3339)
+		remaining := session.NextChange.Sub(now).Seconds()
+		if remaining < 0 {
+			remaining = 0
+		}
+		status["remainingSeconds"] = remaining
+	}
+	return status
+}
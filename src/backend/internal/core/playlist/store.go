@@ -0,0 +1,251 @@
+package playlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+	"linux-wallpaperengine-gui/src/backend/internal/core/wallpaper"
+	"linux-wallpaperengine-gui/src/backend/internal/logger"
+)
+
+const (
+	StoreAuto            = "auto"
+	StoreNative          = "native"
+	StoreWallpaperEngine = "wallpaperengine"
+)
+
+// Store is a place playlists are kept: Wallpaper Engine's own config.json or
+// the GUI's native playlist file. Update runs change on the playlists as they
+// are stored right now and saves the result, holding the store's lock
+// throughout so concurrent edits are not lost. The change may run more than
+// once and must only depend on the playlists it is given.
+type Store interface {
+	Name() string
+	Load() ([]Playlist, error)
+	Update(change func(playlists []Playlist) ([]Playlist, error)) error
+}
+
+// errNoChange is returned by an Update change that leaves the playlists as
+// they are, so that nothing is written.
+var errNoChange = errors.New("playlists unchanged")
+
+// ActiveStore returns the store selected by AppConfig.PlaylistStore. Auto
+// mode is only resolved once: Wallpaper Engine's config.json is picked when it
+// exists, so playlists stay shared with Wallpaper Engine, and the native store
+// otherwise. The choice is then saved to the config, so playlists never move
+// to another store behind the user's back when Wallpaper Engine shows up or
+// goes away later.
+func ActiveStore() (Store, error) {
+	appConfig, err := config.ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if store := configuredStore(appConfig.PlaylistStore); store != nil {
+		return store, nil
+	}
+
+	activeStoreMutex.Lock()
+	defer activeStoreMutex.Unlock()
+
+	// Another caller may have resolved auto mode in the meantime.
+	if appConfig, err = config.ReadConfig(); err != nil {
+		return nil, err
+	}
+	if store := configuredStore(appConfig.PlaylistStore); store != nil {
+		return store, nil
+	}
+
+	store := NativeStore()
+	if _, err := wallpaper.GetWEConfigPath(); err == nil {
+		store = WallpaperEngineStore()
+	}
+	appConfig.PlaylistStore = store.Name()
+	if err := config.WriteConfig(appConfig); err != nil {
+		logger.Printf("Failed to save playlist store %q: %v", store.Name(), err)
+	} else {
+		logger.Printf("Playlist store set to %q", store.Name())
+	}
+	return store, nil
+}
+
+var activeStoreMutex sync.Mutex
+
+// configuredStore returns the store named by mode, or nil for auto mode.
+func configuredStore(mode string) Store {
+	switch mode {
+	case StoreNative:
+		return NativeStore()
+	case StoreWallpaperEngine:
+		return WallpaperEngineStore()
+	}
+	return nil
+}
+
+type weConfigStore struct{}
+
+func WallpaperEngineStore() Store {
+	return weConfigStore{}
+}
+
+func (store weConfigStore) Name() string {
+	return StoreWallpaperEngine
+}
+
+func (store weConfigStore) Load() ([]Playlist, error) {
+	configPath, err := wallpaper.GetWEConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return loadWEPlaylists(configPath)
+}
+
+func (store weConfigStore) Update(change func(playlists []Playlist) ([]Playlist, error)) error {
+	configPath, err := wallpaper.GetWEConfigPath()
+	if err != nil {
+		return err
+	}
+	return updateWEPlaylists(configPath, change)
+}
+
+type nativeStore struct {
+	path string
+}
+
+var nativeStoreMutex sync.Mutex
+
+// NativeStore keeps playlists next to the GUI's own config.json, using the
+// same model as Wallpaper Engine so they can be moved between the two.
+func NativeStore() Store {
+	return nativeStore{path: filepath.Join(filepath.Dir(config.ConfigPath), "playlists.json")}
+}
+
+type nativeStoreFile struct {
+	Playlists []Playlist `json:"playlists"`
+}
+
+func (store nativeStore) Name() string {
+	return StoreNative
+}
+
+func (store nativeStore) Load() ([]Playlist, error) {
+	nativeStoreMutex.Lock()
+	defer nativeStoreMutex.Unlock()
+	return store.read()
+}
+
+func (store nativeStore) read() ([]Playlist, error) {
+	data, err := os.ReadFile(store.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Playlist{}, nil
+		}
+		return nil, fmt.Errorf("failed to read playlists.json: %w", err)
+	}
+
+	var file nativeStoreFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse playlists.json: %w", err)
+	}
+	if file.Playlists == nil {
+		file.Playlists = []Playlist{}
+	}
+	return file.Playlists, nil
+}
+
+func (store nativeStore) Update(change func(playlists []Playlist) ([]Playlist, error)) error {
+	nativeStoreMutex.Lock()
+	defer nativeStoreMutex.Unlock()
+
+	playlists, err := store.read()
+	if err != nil {
+		return err
+	}
+	if playlists, err = change(playlists); err != nil {
+		if errors.Is(err, errNoChange) {
+			return nil
+		}
+		return err
+	}
+	return store.write(playlists)
+}
+
+func (store nativeStore) write(playlists []Playlist) error {
+	if err := os.MkdirAll(filepath.Dir(store.path), 0755); err != nil {
+		return err
+	}
+
+	if playlists == nil {
+		playlists = []Playlist{}
+	}
+	data, err := json.MarshalIndent(nativeStoreFile{Playlists: playlists}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal playlists: %w", err)
+	}
+
+	temporaryPath := store.path + ".tmp"
+	if err := os.WriteFile(temporaryPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, store.path)
+}
+
+// CopyPlaylists merges playlists from one store into another by name. Names
+// that already exist in the destination are replaced only when overwrite is
+// set. When names is non-empty, only those playlists are copied. It returns
+// the names that were written.
+func CopyPlaylists(source Store, destination Store, names []string, overwrite bool) ([]string, error) {
+	sourcePlaylists, err := source.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s playlists: %w", source.Name(), err)
+	}
+
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		selected[name] = true
+	}
+
+	smartPlaylistMutex.Lock()
+	defer smartPlaylistMutex.Unlock()
+
+	copied := []string{}
+	err = destination.Update(func(destinationPlaylists []Playlist) ([]Playlist, error) {
+		existing := make(map[string]int, len(destinationPlaylists))
+		for i, playlist := range destinationPlaylists {
+			existing[playlist.Name] = i
+		}
+
+		copied = []string{}
+		for _, playlist := range sourcePlaylists {
+			if len(selected) > 0 && !selected[playlist.Name] {
+				continue
+			}
+			if err := checkPlaylistName(playlist.Name); err != nil {
+				return nil, err
+			}
+			if index, exists := existing[playlist.Name]; exists {
+				if !overwrite {
+					continue
+				}
+				destinationPlaylists[index] = playlist
+			} else {
+				existing[playlist.Name] = len(destinationPlaylists)
+				destinationPlaylists = append(destinationPlaylists, playlist)
+			}
+			copied = append(copied, playlist.Name)
+		}
+
+		if len(copied) == 0 {
+			return nil, errNoChange
+		}
+		return destinationPlaylists, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save %s playlists: %w", destination.Name(), err)
+	}
+	return copied, nil
+}
@@ -0,0 +1,238 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+	"linux-wallpaperengine-gui/src/backend/internal/core/wallpaper"
+	"linux-wallpaperengine-gui/src/backend/internal/logger"
+)
+
+const (
+	// IssueMissing is an item whose wallpaper folder is gone, usually an
+	// unsubscribed workshop item.
+	IssueMissing = "missing"
+	// IssueInvalidProject is an item whose project.json is absent or cannot be
+	// parsed.
+	IssueInvalidProject = "invalid-project"
+	// IssueUnsupportedType is a wallpaper linux-wallpaperengine cannot render.
+	IssueUnsupportedType = "unsupported-type"
+	// IssueUnresolved is an item whose path cannot be mapped to this machine.
+	IssueUnresolved = "unresolved"
+)
+
+// ItemIssue describes why a playlist item cannot be played.
+type ItemIssue struct {
+	Item   string `json:"item"`
+	Index  int    `json:"index"`
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+}
+
+// PlaylistReport is the result of validating one playlist.
+type PlaylistReport struct {
+	Name      string      `json:"name"`
+	ItemCount int         `json:"itemCount"`
+	Playable  int         `json:"playable"`
+	Issues    []ItemIssue `json:"issues"`
+}
+
+// RepairResult lists what RepairPlaylist changed and what is still wrong.
+type RepairResult struct {
+	Removed  []string          `json:"removed"`
+	Remapped map[string]string `json:"remapped"`
+	Issues   []ItemIssue       `json:"issues"`
+}
+
+// ValidatePlaylists checks every item of every playlist in the active store.
+func ValidatePlaylists() ([]PlaylistReport, error) {
+	playlists, err := GetPlaylists()
+	if err != nil {
+		return nil, err
+	}
+	if err := config.EnsureInitialized(); err != nil {
+		return nil, err
+	}
+
+	reports := make([]PlaylistReport, 0, len(playlists))
+	for _, playlist := range playlists {
+		issues := validateItems(playlist.Items)
+		reports = append(reports, PlaylistReport{
+			Name:      playlist.Name,
+			ItemCount: len(playlist.Items),
+			Playable:  len(playlist.Items) - len(issues),
+			Issues:    issues,
+		})
+	}
+	return reports, nil
+}
+
+func validateItems(items []string) []ItemIssue {
+	issues := []ItemIssue{}
+	for index, item := range items {
+		if issue := validateItem(item); issue != nil {
+			issue.Index = index
+			issues = append(issues, *issue)
+		}
+	}
+	return issues
+}
+
+// validateItem returns nil for a playable item and the reason it cannot be
+// played otherwise.
+func validateItem(item string) *ItemIssue {
+	reference, err := resolveItem(item)
+	if err != nil {
+		kind := IssueUnresolved
+		switch {
+		case errors.Is(err, errNotInstalled):
+			kind = IssueMissing
+		case errors.Is(err, errNoProject):
+			kind = IssueInvalidProject
+		}
+		return &ItemIssue{Item: item, Kind: kind, Reason: err.Error()}
+	}
+
+	projectData, err := wallpaper.ReadWallpaperInfo(reference)
+	if err != nil {
+		return &ItemIssue{Item: item, Kind: IssueInvalidProject, Reason: fmt.Sprintf("failed to read project.json: %v", err)}
+	}
+	if !wallpaper.SupportedType(projectData.Type) {
+		return &ItemIssue{Item: item, Kind: IssueUnsupportedType, Reason: fmt.Sprintf("wallpaper type '%s' is not supported", projectData.Type)}
+	}
+	return nil
+}
+
+// RepairPlaylist fixes a playlist's broken items. Items listed in remap are
+// replaced by their target, which may be a workshop ID or a wallpaper path
+// and must itself be playable; an empty target removes the item. With prune
+// set, every other broken item is removed as well.
+func RepairPlaylist(name string, prune bool, remap map[string]string) (*RepairResult, error) {
+	if err := config.EnsureInitialized(); err != nil {
+		return nil, err
+	}
+
+	replacements := make(map[string]string, len(remap))
+	for item, target := range remap {
+		if target == "" {
+			replacements[item] = ""
+			continue
+		}
+		if issue := validateItem(target); issue != nil {
+			return nil, fmt.Errorf("cannot remap '%s' to '%s': %s", item, target, issue.Reason)
+		}
+		replacement, err := playlistItemFor(target)
+		if err != nil {
+			return nil, err
+		}
+		replacements[item] = replacement
+	}
+
+	var result *RepairResult
+	err := UpdatePlaylists(func(playlists []Playlist) ([]Playlist, error) {
+		var selected *Playlist
+		for i := range playlists {
+			if playlists[i].Name == name {
+				selected = &playlists[i]
+				break
+			}
+		}
+		if selected == nil {
+			return nil, fmt.Errorf("playlist '%s' not found", name)
+		}
+
+		result = &RepairResult{Removed: []string{}, Remapped: map[string]string{}}
+		items := []string{}
+		for _, item := range selected.Items {
+			if replacement, ok := replacements[item]; ok {
+				if replacement == "" {
+					result.Removed = append(result.Removed, item)
+				} else {
+					result.Remapped[item] = replacement
+					items = append(items, replacement)
+				}
+				continue
+			}
+			if prune && validateItem(item) != nil {
+				result.Removed = append(result.Removed, item)
+				continue
+			}
+			items = append(items, item)
+		}
+		result.Issues = validateItems(items)
+
+		if len(result.Removed) == 0 && len(result.Remapped) == 0 {
+			return nil, errNoChange
+		}
+		selected.Items = items
+		return playlists, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// playlistItemFor turns a workshop ID or wallpaper path into the form
+// Wallpaper Engine stores in playlists: the path of the project.json.
+func playlistItemFor(target string) (string, error) {
+	reference, err := resolveItem(target)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(wallpaper.WallpaperPath(reference), "project.json"), nil
+}
+
+// playableWallpapers returns the session's wallpapers that are installed
+// right now. Missing ones, such as unsubscribed workshop items, are only left
+// out of this pick and stay in the playlist, so they play again once they are
+// back, as does anything that merely looked missing for a moment.
+func (session *Session) playableWallpapers() []string {
+	playable := make([]string, 0, len(session.Wallpapers))
+	for _, wallpaperID := range session.Wallpapers {
+		if session.playable(wallpaperID) {
+			playable = append(playable, wallpaperID)
+		}
+	}
+	return playable
+}
+
+// playable reports whether a wallpaper's project.json exists, logging each
+// wallpaper once when it goes missing.
+func (session *Session) playable(wallpaperID string) bool {
+	if _, err := os.Stat(filepath.Join(wallpaper.WallpaperPath(wallpaperID), "project.json")); err != nil {
+		if !session.missing[wallpaperID] {
+			logger.Printf("Skipping missing wallpaper '%s' on screen %s", wallpaperID, session.ScreenName)
+			if session.missing == nil {
+				session.missing = make(map[string]bool)
+			}
+			session.missing[wallpaperID] = true
+		}
+		return false
+	}
+	delete(session.missing, wallpaperID)
+	return true
+}
+
+// takePlayable removes and returns the first playable wallpaper from the
+// front, or with last set the back, of a list of one-off picks such as the
+// queue, dropping missing ones it passes.
+func (session *Session) takePlayable(list *[]string, last bool) string {
+	for len(*list) > 0 {
+		var wallpaperID string
+		if last {
+			wallpaperID = (*list)[len(*list)-1]
+			*list = (*list)[:len(*list)-1]
+		} else {
+			wallpaperID = (*list)[0]
+			*list = (*list)[1:]
+		}
+		if session.playable(wallpaperID) {
+			return wallpaperID
+		}
+	}
+	return ""
+}
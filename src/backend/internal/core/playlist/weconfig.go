@@ -0,0 +1,271 @@
+package playlist
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+	"linux-wallpaperengine-gui/src/backend/internal/logger"
+)
+
+const (
+	// maxConfigBackups is how many copies of Wallpaper Engine's config.json are
+	// kept before older ones are deleted.
+	maxConfigBackups = 10
+	// maxWriteAttempts bounds how often a change is applied again when
+	// config.json keeps changing underneath it.
+	maxWriteAttempts = 3
+)
+
+func parseWEPlaylists(data []byte) ([]Playlist, error) {
+	var weConfig WallpaperEngineConfig
+	if err := json.Unmarshal(data, &weConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse config.json: %w", err)
+	}
+	return weConfig.SteamUser.General.Playlists, nil
+}
+
+func loadWEPlaylists(configPath string) ([]Playlist, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config.json: %w", err)
+	}
+
+	return parseWEPlaylists(data)
+}
+
+// lockWEConfig takes an advisory lock next to config.json so that several
+// instances of the GUI never write it at the same time.
+func lockWEConfig(configPath string) (func(), error) {
+	lockFile, err := os.OpenFile(configPath+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config.json lock: %w", err)
+	}
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX); err != nil {
+		lockFile.Close()
+		return nil, fmt.Errorf("failed to lock config.json: %w", err)
+	}
+	return func() {
+		syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+		lockFile.Close()
+	}, nil
+}
+
+// updateWEPlaylists changes the playlists in config.json. The change runs
+// against the file as it is now, read while holding the lock, so edits made by
+// Wallpaper Engine or another instance since the playlists were last shown are
+// kept. Wallpaper Engine does not take the lock, so if the file changes while
+// the change runs, it is applied again to the new contents. Only the version
+// that is actually replaced is backed up, and the new one is written to a
+// temporary file and renamed into place.
+func updateWEPlaylists(configPath string, change func(playlists []Playlist) ([]Playlist, error)) error {
+	unlock, err := lockWEConfig(configPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for attempt := 1; ; attempt++ {
+		current, info, err := readWEConfig(configPath)
+		if err != nil {
+			return err
+		}
+
+		playlists, err := parseWEPlaylists(current)
+		if err != nil {
+			return err
+		}
+		updated, err := change(playlists)
+		if errors.Is(err, errNoChange) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		data, err := replaceWEPlaylists(current, updated)
+		if err != nil {
+			return err
+		}
+
+		changed, err := weConfigChanged(configPath, info, current)
+		if err != nil {
+			return err
+		}
+		if changed {
+			if attempt < maxWriteAttempts {
+				logger.Printf("config.json changed while saving playlists, applying the change again")
+				continue
+			}
+			return fmt.Errorf("config.json keeps changing, giving up after %d attempts", attempt)
+		}
+
+		if err := backupWEConfig(configPath, current); err != nil {
+			logger.Printf("Failed to back up config.json: %v", err)
+		}
+		if err := writeFileAtomic(configPath, data); err != nil {
+			return fmt.Errorf("failed to write config.json: %w", err)
+		}
+		return nil
+	}
+}
+
+// readWEConfig reads config.json together with the size and modification time
+// of the version read.
+func readWEConfig(configPath string) ([]byte, os.FileInfo, error) {
+	file, err := os.Open(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config.json: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config.json: %w", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config.json: %w", err)
+	}
+	return data, info, nil
+}
+
+// weConfigChanged reports whether config.json differs from the version read
+// earlier. The same size and modification time mean it is untouched, and a
+// different size that it changed. When only the time moved, the contents
+// decide: a file that was touched or saved again unchanged is not a change,
+// while a rewrite of the same size is.
+func weConfigChanged(configPath string, read os.FileInfo, contents []byte) (bool, error) {
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to check config.json: %w", err)
+	}
+	if info.Size() != read.Size() {
+		return true, nil
+	}
+	if info.ModTime().Equal(read.ModTime()) {
+		return false, nil
+	}
+
+	latest, err := os.ReadFile(configPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to check config.json: %w", err)
+	}
+	return sha256.Sum256(latest) != sha256.Sum256(contents), nil
+}
+
+// replaceWEPlaylists swaps the playlists array in config.json, keeping every
+// other field as it is.
+func replaceWEPlaylists(data []byte, playlists []Playlist) ([]byte, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config.json: %w", err)
+	}
+
+	steamUser, ok := root["steamuser"].(map[string]interface{})
+	if !ok {
+		steamUser = make(map[string]interface{})
+		root["steamuser"] = steamUser
+	}
+
+	general, ok := steamUser["general"].(map[string]interface{})
+	if !ok {
+		general = make(map[string]interface{})
+		steamUser["general"] = general
+	}
+
+	// Convert playlists slice into what json.Unmarshal would produce
+	// for generic map[string]interface{} so we can seamlessly insert it
+	pBytes, err := json.Marshal(playlists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal playlists: %w", err)
+	}
+
+	var genericPlaylists interface{}
+	if err := json.Unmarshal(pBytes, &genericPlaylists); err != nil {
+		return nil, fmt.Errorf("failed to prepare playlists for save: %w", err)
+	}
+
+	general["playlists"] = genericPlaylists
+
+	// Marshal back with indentation matching WE
+	newData, err := json.MarshalIndent(root, "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config.json: %w", err)
+	}
+	return newData, nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	temporary, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	temporaryPath := temporary.Name()
+	defer os.Remove(temporaryPath)
+
+	if _, err := temporary.Write(data); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Sync(); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(temporaryPath, mode); err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, path)
+}
+
+func weConfigBackupDir() string {
+	return filepath.Join(config.StateDir, "we-config-backups")
+}
+
+// backupWEConfig keeps a timestamped copy of config.json and prunes the oldest
+// copies. Unchanged files are not backed up twice.
+func backupWEConfig(configPath string, data []byte) error {
+	backupDir := weConfigBackupDir()
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return err
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(backupDir, "config-*.json"))
+	sort.Strings(backups)
+	if len(backups) > 0 {
+		if latest, err := os.ReadFile(backups[len(backups)-1]); err == nil && bytes.Equal(latest, data) {
+			return nil
+		}
+	}
+
+	backupPath := filepath.Join(backupDir, "config-"+time.Now().Format("20060102-150405.000")+".json")
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		return err
+	}
+	backups = append(backups, backupPath)
+
+	for len(backups) > maxConfigBackups {
+		if err := os.Remove(backups[0]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
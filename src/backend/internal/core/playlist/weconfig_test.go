@@ -0,0 +1,226 @@
+package playlist
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+)
+
+const testWEConfig = `{
+	"steamuser": {
+		"general": {
+			"playlists": [
+				{"name": "day", "items": ["a/project.json"], "settings": {"delay": 60}},
+				{"name": "night", "items": ["b/project.json"], "settings": {"delay": 60}}
+			],
+			"keep": true
+		}
+	},
+	"other": {"wproperties": {}}
+}`
+
+// writeTestWEConfig writes testWEConfig, with its playlists replaced by
+// empty ones of the given names if any are given.
+func writeTestWEConfig(t *testing.T, path string, names ...string) {
+	t.Helper()
+	data := []byte(testWEConfig)
+	if names != nil {
+		playlists := []Playlist{}
+		for _, name := range names {
+			playlists = append(playlists, Playlist{Name: name, Items: []string{}})
+		}
+		var err error
+		if data, err = replaceWEPlaylists(data, playlists); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func playlistNames(playlists []Playlist) []string {
+	names := []string{}
+	for _, playlist := range playlists {
+		names = append(names, playlist.Name)
+	}
+	return names
+}
+
+func TestUpdateWEPlaylists(t *testing.T) {
+	errRejected := errors.New("rejected")
+	addEvening := func(playlists []Playlist) ([]Playlist, error) {
+		return append(playlists, Playlist{Name: "evening", Items: []string{}}), nil
+	}
+
+	tests := []struct {
+		name string
+		// change builds the change for the config.json at path; calls counts
+		// how often it ran.
+		change      func(path string, calls *int) func([]Playlist) ([]Playlist, error)
+		want        []string
+		wantCalls   int
+		wantBackups int
+		wantErr     bool
+	}{
+		{
+			name: "applies the change",
+			change: func(path string, calls *int) func([]Playlist) ([]Playlist, error) {
+				return func(playlists []Playlist) ([]Playlist, error) {
+					*calls++
+					return addEvening(playlists)
+				}
+			},
+			want:        []string{"day", "night", "evening"},
+			wantCalls:   1,
+			wantBackups: 1,
+		},
+		{
+			name: "keeps a change made while the change ran",
+			change: func(path string, calls *int) func([]Playlist) ([]Playlist, error) {
+				return func(playlists []Playlist) ([]Playlist, error) {
+					*calls++
+					if *calls == 1 {
+						writeTestWEConfig(t, path, "day", "night", "dawn")
+					}
+					return addEvening(playlists)
+				}
+			},
+			want:        []string{"day", "night", "dawn", "evening"},
+			wantCalls:   2,
+			wantBackups: 1,
+		},
+		{
+			name: "writes a file that was only touched",
+			change: func(path string, calls *int) func([]Playlist) ([]Playlist, error) {
+				return func(playlists []Playlist) ([]Playlist, error) {
+					*calls++
+					later := time.Now().Add(time.Minute)
+					if err := os.Chtimes(path, later, later); err != nil {
+						t.Fatal(err)
+					}
+					return addEvening(playlists)
+				}
+			},
+			want:        []string{"day", "night", "evening"},
+			wantCalls:   1,
+			wantBackups: 1,
+		},
+		{
+			name: "notices a rewrite of the same size",
+			change: func(path string, calls *int) func([]Playlist) ([]Playlist, error) {
+				return func(playlists []Playlist) ([]Playlist, error) {
+					*calls++
+					if *calls == 1 {
+						data, err := os.ReadFile(path)
+						if err != nil {
+							t.Fatal(err)
+						}
+						if err := os.WriteFile(path, []byte(strings.Replace(string(data), "night", "NIGHT", 1)), 0644); err != nil {
+							t.Fatal(err)
+						}
+						later := time.Now().Add(time.Minute)
+						if err := os.Chtimes(path, later, later); err != nil {
+							t.Fatal(err)
+						}
+					}
+					return addEvening(playlists)
+				}
+			},
+			want:        []string{"day", "NIGHT", "evening"},
+			wantCalls:   2,
+			wantBackups: 1,
+		},
+		{
+			name: "gives up when the file keeps changing",
+			change: func(path string, calls *int) func([]Playlist) ([]Playlist, error) {
+				return func(playlists []Playlist) ([]Playlist, error) {
+					*calls++
+					writeTestWEConfig(t, path, "day", "night", "dawn")
+					if *calls%2 == 0 {
+						writeTestWEConfig(t, path)
+					}
+					return addEvening(playlists)
+				}
+			},
+			want:      []string{"day", "night", "dawn"},
+			wantCalls: maxWriteAttempts,
+			wantErr:   true,
+		},
+		{
+			name: "writes nothing when the change fails",
+			change: func(path string, calls *int) func([]Playlist) ([]Playlist, error) {
+				return func(playlists []Playlist) ([]Playlist, error) {
+					*calls++
+					return nil, errRejected
+				}
+			},
+			want:      []string{"day", "night"},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name: "writes nothing when nothing changed",
+			change: func(path string, calls *int) func([]Playlist) ([]Playlist, error) {
+				return func(playlists []Playlist) ([]Playlist, error) {
+					*calls++
+					return nil, errNoChange
+				}
+			},
+			want:      []string{"day", "night"},
+			wantCalls: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			directory := t.TempDir()
+			config.StateDir = filepath.Join(directory, "state")
+			path := filepath.Join(directory, "config.json")
+			writeTestWEConfig(t, path)
+
+			calls := 0
+			err := updateWEPlaylists(path, test.change(path, &calls))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("error = %v, want error: %v", err, test.wantErr)
+			}
+			if calls != test.wantCalls {
+				t.Errorf("change ran %d times, want %d", calls, test.wantCalls)
+			}
+			if backups, _ := filepath.Glob(filepath.Join(weConfigBackupDir(), "config-*.json")); len(backups) != test.wantBackups {
+				t.Errorf("%d backups written, want %d", len(backups), test.wantBackups)
+			}
+
+			playlists, err := loadWEPlaylists(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := playlistNames(playlists); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("playlists = %v, want %v", got, test.want)
+			}
+
+			data, _ := os.ReadFile(path)
+			var root struct {
+				SteamUser struct {
+					General struct {
+						Keep bool `json:"keep"`
+					} `json:"general"`
+				} `json:"steamuser"`
+				Other map[string]interface{} `json:"other"`
+			}
+			if err := json.Unmarshal(data, &root); err != nil {
+				t.Fatal(err)
+			}
+			if !root.SteamUser.General.Keep || root.Other == nil {
+				t.Errorf("fields outside the playlists were lost: %s", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,494 @@
+package preset
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+	"linux-wallpaperengine-gui/src/backend/internal/core/playlist"
+	"linux-wallpaperengine-gui/src/backend/internal/core/wallpaper"
+)
+
+// WallpaperEnginePresetName is the preset that holds the values Wallpaper
+// Engine itself stored for a wallpaper.
+const WallpaperEnginePresetName = "Wallpaper Engine"
+
+// Preset is a named set of property values for one wallpaper, stored the same
+// way as AppConfig.WallpaperProperties.
+type Preset struct {
+	Name       string            `json:"name"`
+	Properties map[string]string `json:"properties"`
+}
+
+// ImportResult is a preset created by an import, with the values that were
+// left out because the wallpaper does not accept them.
+type ImportResult struct {
+	WallpaperID string   `json:"wallpaperId"`
+	Preset      Preset   `json:"preset"`
+	Skipped     []string `json:"skipped"`
+}
+
+type presetsFile struct {
+	Presets map[string][]Preset `json:"presets"`
+}
+
+var presetsMutex sync.Mutex
+
+func presetsPath() string {
+	return filepath.Join(filepath.Dir(config.ConfigPath), "presets.json")
+}
+
+// Key returns the presets key of a wallpaper reference, which is its folder
+// name whether the reference is a workshop ID or an absolute path.
+func Key(wallpaperID string) string {
+	return filepath.Base(wallpaperID)
+}
+
+func readPresets() (map[string][]Preset, error) {
+	data, err := os.ReadFile(presetsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string][]Preset), nil
+		}
+		return nil, fmt.Errorf("failed to read presets.json: %w", err)
+	}
+
+	var file presetsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse presets.json: %w", err)
+	}
+	if file.Presets == nil {
+		file.Presets = make(map[string][]Preset)
+	}
+	return file.Presets, nil
+}
+
+func writePresets(presets map[string][]Preset) error {
+	path := presetsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(presetsFile{Presets: presets}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal presets: %w", err)
+	}
+
+	temporaryPath := path + ".tmp"
+	if err := os.WriteFile(temporaryPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, path)
+}
+
+func GetAllPresets() (map[string][]Preset, error) {
+	presetsMutex.Lock()
+	defer presetsMutex.Unlock()
+	return readPresets()
+}
+
+func GetPresets(wallpaperID string) ([]Preset, error) {
+	presets, err := GetAllPresets()
+	if err != nil {
+		return nil, err
+	}
+	if list, ok := presets[Key(wallpaperID)]; ok {
+		return list, nil
+	}
+	return []Preset{}, nil
+}
+
+func GetPreset(wallpaperID string, name string) (*Preset, error) {
+	presets, err := GetPresets(wallpaperID)
+	if err != nil {
+		return nil, err
+	}
+	if index := indexOf(presets, name); index >= 0 {
+		return &presets[index], nil
+	}
+	return nil, fmt.Errorf("preset '%s' not found for wallpaper %s", name, wallpaperID)
+}
+
+// SavePreset stores a preset, validating its values against the wallpaper's
+// properties. Without properties, the wallpaper's current values are saved.
+// An existing preset of the same name is only replaced when overwrite is set.
+func SavePreset(wallpaperID string, preset Preset, overwrite bool) (*Preset, error) {
+	preset.Name = strings.TrimSpace(preset.Name)
+	if preset.Name == "" {
+		return nil, fmt.Errorf("preset name is required")
+	}
+
+	if preset.Properties == nil {
+		appConfig, err := config.ReadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		preset.Properties = copyProperties(appConfig.WallpaperProperties[wallpaperID])
+	}
+
+	current := make(map[string]map[string]string)
+	if err := wallpaper.ValidateWallpaperProperties(current, map[string]map[string]string{wallpaperID: preset.Properties}); err != nil {
+		return nil, err
+	}
+
+	err := update(wallpaperID, func(presets []Preset) ([]Preset, error) {
+		if index := indexOf(presets, preset.Name); index >= 0 {
+			if !overwrite {
+				return nil, fmt.Errorf("a preset named '%s' already exists", preset.Name)
+			}
+			presets[index] = preset
+			return presets, nil
+		}
+		return append(presets, preset), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+func RenamePreset(wallpaperID string, oldName string, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return fmt.Errorf("preset name is required")
+	}
+	return update(wallpaperID, func(presets []Preset) ([]Preset, error) {
+		index := indexOf(presets, oldName)
+		if index < 0 {
+			return nil, fmt.Errorf("preset '%s' not found", oldName)
+		}
+		if oldName != newName && indexOf(presets, newName) >= 0 {
+			return nil, fmt.Errorf("a preset named '%s' already exists", newName)
+		}
+		presets[index].Name = newName
+		return presets, nil
+	})
+}
+
+func DeletePreset(wallpaperID string, name string) error {
+	return update(wallpaperID, func(presets []Preset) ([]Preset, error) {
+		index := indexOf(presets, name)
+		if index < 0 {
+			return nil, fmt.Errorf("preset '%s' not found", name)
+		}
+		return append(presets[:index], presets[index+1:]...), nil
+	})
+}
+
+func update(wallpaperID string, change func(presets []Preset) ([]Preset, error)) error {
+	if wallpaperID == "" {
+		return fmt.Errorf("wallpaperId is required")
+	}
+
+	presetsMutex.Lock()
+	defer presetsMutex.Unlock()
+
+	presets, err := readPresets()
+	if err != nil {
+		return err
+	}
+
+	key := Key(wallpaperID)
+	list, err := change(append([]Preset{}, presets[key]...))
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		delete(presets, key)
+	} else {
+		presets[key] = list
+	}
+
+	if err := writePresets(presets); err != nil {
+		return fmt.Errorf("failed to save presets: %w", err)
+	}
+	return nil
+}
+
+func indexOf(presets []Preset, name string) int {
+	for i := range presets {
+		if presets[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func copyProperties(properties map[string]string) map[string]string {
+	copied := make(map[string]string, len(properties))
+	for name, value := range properties {
+		copied[name] = value
+	}
+	return copied
+}
+
+// ApplyPreset makes a preset's values the wallpaper's properties. With a
+// screen name, the wallpaper is shown on that screen, or on every screen in
+// clone and span mode, and the values become that screen's overrides instead,
+// leaving other screens showing the wallpaper untouched. A spanned wallpaper
+// has no single screen, so it always takes the values itself. The caller
+// applies the changed config.
+func ApplyPreset(wallpaperID string, name string, screenName string) error {
+	preset, err := GetPreset(wallpaperID, name)
+	if err != nil {
+		return err
+	}
+
+	appConfig, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if screenName == "" || appConfig.SpanMode {
+		if appConfig.WallpaperProperties == nil {
+			appConfig.WallpaperProperties = make(map[string]map[string]string)
+		}
+		appConfig.WallpaperProperties[wallpaperID] = copyProperties(preset.Properties)
+	}
+
+	if screenName != "" {
+		index := -1
+		for i := range appConfig.Screens {
+			if appConfig.Screens[i].Name == screenName {
+				index = i
+				break
+			}
+		}
+		if index < 0 && !appConfig.SpanMode {
+			return fmt.Errorf("screen '%s' not found", screenName)
+		}
+
+		if appConfig.CloneMode || appConfig.SpanMode {
+			globalWallpaper := wallpaperID
+			appConfig.GlobalWallpaper = &globalWallpaper
+		} else {
+			screenWallpaper := wallpaperID
+			appConfig.Screens[index].Wallpaper = &screenWallpaper
+		}
+
+		if !appConfig.SpanMode {
+			screen := &appConfig.Screens[index]
+			if screen.WallpaperProperties == nil {
+				screen.WallpaperProperties = make(map[string]map[string]string)
+			}
+			screen.WallpaperProperties[wallpaperID] = copyProperties(preset.Properties)
+		}
+	}
+
+	if err := config.WriteConfig(appConfig); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// ImportPresetFile imports a Wallpaper Engine preset: the project.json of a
+// preset item, or its folder, whose "dependency" names the wallpaper and whose
+// "preset" object holds the values. A plain object of property values, or one
+// with a "properties" object, is accepted too when wallpaperID is given. The
+// preset is named after the file's title unless name is given, and renamed if
+// the name is taken.
+func ImportPresetFile(path string, wallpaperID string, name string) (*ImportResult, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "project.json")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preset file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse preset file: %w", err)
+	}
+
+	values := raw
+	if preset, ok := raw["preset"].(map[string]interface{}); ok {
+		values = preset
+	} else if properties, ok := raw["properties"].(map[string]interface{}); ok {
+		values = properties
+	}
+
+	if wallpaperID == "" {
+		if dependency, ok := presetValue(raw["dependency"]); ok {
+			wallpaperID = dependency
+		}
+	}
+	if wallpaperID == "" {
+		return nil, fmt.Errorf("the preset does not name its wallpaper, pass wallpaperId")
+	}
+
+	if name == "" {
+		if title, ok := raw["title"].(string); ok {
+			name = strings.TrimSpace(title)
+		}
+	}
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if name == "project" {
+			name = filepath.Base(filepath.Dir(path))
+		}
+	}
+
+	if err := config.EnsureInitialized(); err != nil {
+		return nil, err
+	}
+	properties, skipped := acceptedValues(wallpaperID, values)
+
+	var result *ImportResult
+	err = update(wallpaperID, func(presets []Preset) ([]Preset, error) {
+		unique := name
+		for suffix := 2; indexOf(presets, unique) >= 0; suffix++ {
+			unique = fmt.Sprintf("%s (%d)", name, suffix)
+		}
+		preset := Preset{Name: unique, Properties: properties}
+		result = &ImportResult{WallpaperID: wallpaperID, Preset: preset, Skipped: skipped}
+		return append(presets, preset), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ImportWEProperties turns the property values Wallpaper Engine stored in its
+// config.json into a "Wallpaper Engine" preset per wallpaper, replacing the
+// one from an earlier import. With a wallpaper ID only that wallpaper is
+// imported.
+func ImportWEProperties(wallpaperID string) ([]ImportResult, error) {
+	configPath, err := wallpaper.GetWEConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config.json: %w", err)
+	}
+
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config.json: %w", err)
+	}
+
+	// Values live under each Steam user, keyed by the wallpaper's project.json.
+	stored := make(map[string]map[string]interface{})
+	for _, user := range root {
+		userConfig, ok := user.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		wproperties, ok := userConfig["wproperties"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for item, values := range wproperties {
+			if valueMap, ok := values.(map[string]interface{}); ok && len(valueMap) > 0 {
+				stored[item] = valueMap
+			}
+		}
+	}
+
+	items := make([]string, 0, len(stored))
+	for item := range stored {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+
+	references, err := playlist.ResolveItemMap(items)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []ImportResult{}
+	for _, item := range items {
+		reference, ok := references[item]
+		if !ok || (wallpaperID != "" && Key(reference) != Key(wallpaperID)) {
+			continue
+		}
+		target := reference
+		if wallpaperID != "" {
+			target = wallpaperID
+		}
+
+		properties, skipped := acceptedValues(target, stored[item])
+		if len(properties) == 0 {
+			continue
+		}
+		preset := Preset{Name: WallpaperEnginePresetName, Properties: properties}
+		err := update(target, func(presets []Preset) ([]Preset, error) {
+			if index := indexOf(presets, preset.Name); index >= 0 {
+				presets[index] = preset
+				return presets, nil
+			}
+			return append(presets, preset), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, ImportResult{WallpaperID: target, Preset: preset, Skipped: skipped})
+	}
+	return results, nil
+}
+
+// acceptedValues converts imported values to the stored text form and drops
+// the ones the wallpaper's properties reject. Without a readable schema every
+// convertible value is kept.
+func acceptedValues(wallpaperID string, values map[string]interface{}) (map[string]string, []string) {
+	schema := make(map[string]wallpaper.WallpaperProperty)
+	if properties, err := wallpaper.ReadWallpaperPropertySchema(wallpaperID); err == nil {
+		for _, property := range properties {
+			schema[property.Name] = property
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	accepted := make(map[string]string)
+	skipped := []string{}
+	for _, name := range names {
+		value, ok := presetValue(values[name])
+		if !ok {
+			skipped = append(skipped, name)
+			continue
+		}
+		if len(schema) > 0 {
+			property, known := schema[name]
+			if !known || wallpaper.ValidatePropertyValue(property, value) != nil {
+				skipped = append(skipped, name)
+				continue
+			}
+		}
+		accepted[name] = value
+	}
+	return accepted, skipped
+}
+
+// presetValue converts a JSON value to the text form property values are
+// stored in. Wallpaper Engine sometimes wraps values as {"value": ...}.
+func presetValue(value interface{}) (string, bool) {
+	switch typed := value.(type) {
+	case string:
+		return typed, true
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), true
+	case bool:
+		if typed {
+			return "1", true
+		}
+		return "0", true
+	case map[string]interface{}:
+		if inner, ok := typed["value"]; ok {
+			return presetValue(inner)
+		}
+	}
+	return "", false
+}
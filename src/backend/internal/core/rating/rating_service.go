@@ -0,0 +1,151 @@
+package rating
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+)
+
+const (
+	MinRating = 1
+	MaxRating = 5
+	// neutralRating is assumed for wallpapers the user has not rated.
+	neutralRating = 3
+)
+
+// Rating is what the user thinks of a wallpaper. A zero Stars value means the
+// wallpaper has not been rated.
+type Rating struct {
+	Favorite bool `json:"favorite,omitempty"`
+	Stars    int  `json:"rating,omitempty"`
+}
+
+type ratingsFile struct {
+	Ratings map[string]Rating `json:"ratings"`
+}
+
+var ratingsMutex sync.Mutex
+
+func ratingsPath() string {
+	return filepath.Join(filepath.Dir(config.ConfigPath), "ratings.json")
+}
+
+// Key returns the ratings key of a wallpaper reference, which is its folder
+// name whether the reference is a workshop ID or an absolute path.
+func Key(wallpaperID string) string {
+	return filepath.Base(wallpaperID)
+}
+
+func readRatings() (map[string]Rating, error) {
+	data, err := os.ReadFile(ratingsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]Rating), nil
+		}
+		return nil, fmt.Errorf("failed to read ratings.json: %w", err)
+	}
+
+	var file ratingsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse ratings.json: %w", err)
+	}
+	if file.Ratings == nil {
+		file.Ratings = make(map[string]Rating)
+	}
+	return file.Ratings, nil
+}
+
+func writeRatings(ratings map[string]Rating) error {
+	path := ratingsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(ratingsFile{Ratings: ratings}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ratings: %w", err)
+	}
+
+	temporaryPath := path + ".tmp"
+	if err := os.WriteFile(temporaryPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, path)
+}
+
+func GetRatings() (map[string]Rating, error) {
+	ratingsMutex.Lock()
+	defer ratingsMutex.Unlock()
+	return readRatings()
+}
+
+func GetRating(wallpaperID string) (Rating, error) {
+	ratings, err := GetRatings()
+	if err != nil {
+		return Rating{}, err
+	}
+	return ratings[Key(wallpaperID)], nil
+}
+
+// SetRating stores a 1-5 star rating. Zero clears it.
+func SetRating(wallpaperID string, stars int) (Rating, error) {
+	if stars != 0 && (stars < MinRating || stars > MaxRating) {
+		return Rating{}, fmt.Errorf("rating must be between %d and %d, or 0 to clear it", MinRating, MaxRating)
+	}
+	return update(wallpaperID, func(rating *Rating) {
+		rating.Stars = stars
+	})
+}
+
+func SetFavorite(wallpaperID string, favorite bool) (Rating, error) {
+	return update(wallpaperID, func(rating *Rating) {
+		rating.Favorite = favorite
+	})
+}
+
+func update(wallpaperID string, change func(rating *Rating)) (Rating, error) {
+	if wallpaperID == "" {
+		return Rating{}, fmt.Errorf("wallpaperId is required")
+	}
+
+	ratingsMutex.Lock()
+	defer ratingsMutex.Unlock()
+
+	ratings, err := readRatings()
+	if err != nil {
+		return Rating{}, err
+	}
+
+	key := Key(wallpaperID)
+	rating := ratings[key]
+	change(&rating)
+	if rating == (Rating{}) {
+		delete(ratings, key)
+	} else {
+		ratings[key] = rating
+	}
+
+	if err := writeRatings(ratings); err != nil {
+		return Rating{}, fmt.Errorf("failed to save ratings: %w", err)
+	}
+	return rating, nil
+}
+
+// Weight is how strongly random playback favors a wallpaper. Each star above
+// or below neutral doubles or halves the chance of being picked, and
+// favorites count twice.
+func (rating Rating) Weight() int {
+	stars := rating.Stars
+	if stars == 0 {
+		stars = neutralRating
+	}
+	weight := 1 << (stars - MinRating)
+	if rating.Favorite {
+		weight *= 2
+	}
+	return weight
+}
@@ -0,0 +1,303 @@
+package wallpaper
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+	"linux-wallpaperengine-gui/src/backend/internal/logger"
+)
+
+// catalogVersion is bumped whenever the cached WallpaperData changes shape,
+// so stale caches are rebuilt instead of misread.
+const catalogVersion = 1
+
+// catalogEntry is a parsed project.json together with the file's modification
+// time and size, which tell whether it has to be parsed again.
+type catalogEntry struct {
+	ProjectModTime int64         `json:"projectModTime"`
+	ProjectSize    int64         `json:"projectSize"`
+	Data           WallpaperData `json:"data"`
+}
+
+type catalogFile struct {
+	Version      int                     `json:"version"`
+	WorkshopPath string                  `json:"workshopPath"`
+	Entries      map[string]catalogEntry `json:"entries"`
+}
+
+// CatalogChanges lists the folders a catalog scan added, removed or found
+// changed.
+type CatalogChanges struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Updated []string `json:"updated"`
+}
+
+func (changes CatalogChanges) Empty() bool {
+	return len(changes.Added) == 0 && len(changes.Removed) == 0 && len(changes.Updated) == 0
+}
+
+// catalog keeps every installed wallpaper in memory and on disk. While the
+// watcher runs, file system events keep it current and reads are served from
+// memory; otherwise each read checks folder and project.json metadata and only
+// parses what changed.
+var catalog struct {
+	sync.Mutex
+	loaded   bool
+	basePath string
+	entries  map[string]catalogEntry
+	watched  bool
+}
+
+func catalogPath() string {
+	return filepath.Join(config.StateDir, "wallpaper-catalog.json")
+}
+
+func readCatalogFile(basePath string) map[string]catalogEntry {
+	entries := make(map[string]catalogEntry)
+	data, err := os.ReadFile(catalogPath())
+	if err != nil {
+		return entries
+	}
+
+	var file catalogFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		logger.Printf("Ignoring unreadable wallpaper catalog: %v", err)
+		return entries
+	}
+	if file.Version != catalogVersion || file.WorkshopPath != basePath || file.Entries == nil {
+		return entries
+	}
+	return file.Entries
+}
+
+func writeCatalogFile(basePath string, entries map[string]catalogEntry) error {
+	if err := os.MkdirAll(config.StateDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(catalogFile{Version: catalogVersion, WorkshopPath: basePath, Entries: entries})
+	if err != nil {
+		return err
+	}
+
+	temporaryPath := catalogPath() + ".tmp"
+	if err := os.WriteFile(temporaryPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, catalogPath())
+}
+
+// loadCatalog makes the catalog describe basePath, starting from the cache on
+// disk, and scans the folder for what changed since it was saved. The caller
+// holds the catalog lock.
+func loadCatalog(basePath string) CatalogChanges {
+	catalog.entries = make(map[string]catalogEntry)
+	if basePath != "" {
+		catalog.entries = readCatalogFile(basePath)
+	}
+	catalog.basePath = basePath
+	catalog.loaded = true
+	return scanCatalog()
+}
+
+// scanCatalog brings the loaded catalog up to date with the workshop folder
+// and saves it when anything changed. The caller holds the catalog lock.
+func scanCatalog() CatalogChanges {
+	changes := CatalogChanges{Added: []string{}, Removed: []string{}, Updated: []string{}}
+
+	var dirEntries []os.DirEntry
+	if catalog.basePath != "" {
+		var err error
+		dirEntries, err = os.ReadDir(catalog.basePath)
+		if err != nil && !os.IsNotExist(err) {
+			logger.Printf("Failed to scan wallpaper folder: %v", err)
+			return changes
+		}
+	}
+
+	seen := make(map[string]bool, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() {
+			continue
+		}
+		folderName := dirEntry.Name()
+		switch refreshCatalogEntry(folderName) {
+		case entryAdded:
+			changes.Added = append(changes.Added, folderName)
+		case entryUpdated:
+			changes.Updated = append(changes.Updated, folderName)
+		case entryRemoved:
+			changes.Removed = append(changes.Removed, folderName)
+		}
+		if _, ok := catalog.entries[folderName]; ok {
+			seen[folderName] = true
+		}
+	}
+
+	for folderName := range catalog.entries {
+		if !seen[folderName] {
+			delete(catalog.entries, folderName)
+			changes.Removed = append(changes.Removed, folderName)
+		}
+	}
+
+	if !changes.Empty() {
+		sort.Strings(changes.Added)
+		sort.Strings(changes.Removed)
+		sort.Strings(changes.Updated)
+		if err := writeCatalogFile(catalog.basePath, catalog.entries); err != nil {
+			logger.Printf("Failed to save wallpaper catalog: %v", err)
+		}
+	}
+	return changes
+}
+
+type entryChange int
+
+const (
+	entryUnchanged entryChange = iota
+	entryAdded
+	entryUpdated
+	entryRemoved
+)
+
+// refreshCatalogEntry re-reads one wallpaper folder if its project.json or
+// install date changed. Folders without a readable project.json are dropped.
+// The caller holds the catalog lock.
+func refreshCatalogEntry(folderName string) entryChange {
+	folderPath := filepath.Join(catalog.basePath, folderName)
+	cached, known := catalog.entries[folderName]
+	drop := func() entryChange {
+		if !known {
+			return entryUnchanged
+		}
+		delete(catalog.entries, folderName)
+		return entryRemoved
+	}
+
+	folderInfo, err := os.Stat(folderPath)
+	if err != nil || !folderInfo.IsDir() {
+		return drop()
+	}
+	projectInfo, err := os.Stat(filepath.Join(folderPath, "project.json"))
+	if err != nil {
+		return drop()
+	}
+
+	installDate := folderInfo.ModTime().Unix()
+	if stat, ok := folderInfo.Sys().(*syscall.Stat_t); ok {
+		installDate = stat.Ctim.Sec
+	}
+
+	if known && cached.ProjectModTime == projectInfo.ModTime().UnixNano() && cached.ProjectSize == projectInfo.Size() {
+		if cached.Data.InstallDate == installDate {
+			return entryUnchanged
+		}
+		cached.Data.InstallDate = installDate
+		catalog.entries[folderName] = cached
+		return entryUpdated
+	}
+
+	projectData, err := readProjectData(filepath.Join(folderPath, "project.json"))
+	if err != nil {
+		return drop()
+	}
+
+	var previewPath string
+	if projectData.Preview != "" {
+		previewPath = "wallpaper://" + filepath.Join(folderPath, projectData.Preview)
+	}
+
+	catalog.entries[folderName] = catalogEntry{
+		ProjectModTime: projectInfo.ModTime().UnixNano(),
+		ProjectSize:    projectInfo.Size(),
+		Data: WallpaperData{
+			ProjectData: projectData,
+			PreviewPath: previewPath,
+			InstallDate: installDate,
+		},
+	}
+	if known {
+		return entryUpdated
+	}
+	return entryAdded
+}
+
+// catalogWallpapers returns a copy of the catalog for basePath.
+func catalogWallpapers(basePath string) map[string]WallpaperData {
+	catalog.Lock()
+	defer catalog.Unlock()
+
+	if !catalog.loaded || catalog.basePath != basePath {
+		loadCatalog(basePath)
+	} else if !catalog.watched {
+		scanCatalog()
+	}
+
+	wallpapers := make(map[string]WallpaperData, len(catalog.entries))
+	for folderName, entry := range catalog.entries {
+		wallpapers[folderName] = entry.Data
+	}
+	return wallpapers
+}
+
+// catalogProjectData returns a workshop wallpaper's project data from the
+// catalog, checking only that wallpaper's folder for changes, and falls back
+// to reading project.json for wallpapers the catalog does not cover. It uses
+// the workshop path as already detected.
+func catalogProjectData(wallpaperID string) (*WallpaperProjectData, error) {
+	if workshopPath := config.GetWorkshopPath(); !filepath.IsAbs(wallpaperID) && workshopPath != "" {
+		catalog.Lock()
+		if catalog.loaded && catalog.basePath == workshopPath {
+			if !catalog.watched {
+				refreshCatalogEntry(wallpaperID)
+			}
+			if entry, ok := catalog.entries[wallpaperID]; ok && entry.Data.ProjectData != nil {
+				catalog.Unlock()
+				return entry.Data.ProjectData, nil
+			}
+		}
+		catalog.Unlock()
+	}
+	return readProjectData(filepath.Join(WallpaperPath(wallpaperID), "project.json"))
+}
+
+// RefreshCatalog rescans the workshop folder, parsing only wallpapers whose
+// project.json changed, and reports what changed.
+func RefreshCatalog() (CatalogChanges, error) {
+	if err := config.EnsureInitialized(); err != nil {
+		return CatalogChanges{}, err
+	}
+
+	catalog.Lock()
+	defer catalog.Unlock()
+	if workshopPath := config.GetWorkshopPath(); !catalog.loaded || catalog.basePath != workshopPath {
+		return loadCatalog(workshopPath), nil
+	}
+	return scanCatalog(), nil
+}
+
+// InvalidateCatalog throws the cache away and parses every wallpaper again.
+func InvalidateCatalog() (CatalogChanges, error) {
+	catalog.Lock()
+	catalog.loaded = false
+	catalog.entries = nil
+	if err := os.Remove(catalogPath()); err != nil && !os.IsNotExist(err) {
+		logger.Printf("Failed to remove wallpaper catalog: %v", err)
+	}
+	catalog.Unlock()
+
+	return RefreshCatalog()
+}
+
+func setCatalogWatched(watched bool) {
+	catalog.Lock()
+	defer catalog.Unlock()
+	catalog.watched = watched
+}
@@ -6,72 +6,64 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"syscall"
 
 	"linux-wallpaperengine-gui/src/backend/internal/config"
 )
 
+// GetWallpapers returns every installed wallpaper keyed by folder name,
+// served from the catalog.
 func GetWallpapers() (map[string]WallpaperData, error) {
 	if err := config.EnsureInitialized(); err != nil {
 		return nil, err
 	}
+	return catalogWallpapers(config.GetWorkshopPath()), nil
+}
 
-	basePath := config.WorkshopPath
-	if basePath == "" {
-		return make(map[string]WallpaperData), nil
-	}
-
-	if _, err := os.Stat(basePath); os.IsNotExist(err) {
-		return make(map[string]WallpaperData), nil
+// WallpaperPath returns the folder of a wallpaper reference: a workshop ID,
+// or an absolute path for wallpapers that live outside the workshop folder.
+func WallpaperPath(wallpaperID string) string {
+	workshopPath := config.GetWorkshopPath()
+	if filepath.IsAbs(wallpaperID) || workshopPath == "" {
+		return wallpaperID
 	}
+	return filepath.Join(workshopPath, wallpaperID)
+}
 
-	entries, err := os.ReadDir(basePath)
-	if err != nil {
+// GetWallpaperInfo reads the project.json of a single wallpaper reference.
+func GetWallpaperInfo(wallpaperID string) (*WallpaperProjectData, error) {
+	if err := config.EnsureInitialized(); err != nil {
 		return nil, err
 	}
+	return ReadWallpaperInfo(wallpaperID)
+}
 
-	wallpapers := make(map[string]WallpaperData)
-
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		folderName := entry.Name()
-		projectJSONPath := filepath.Join(basePath, folderName, "project.json")
-
-		data, err := os.ReadFile(projectJSONPath)
-		if err != nil {
-			continue
-		}
-
-		var projectData WallpaperProjectData
-		if err := json.Unmarshal(data, &projectData); err != nil {
-			continue
-		}
-
-		var previewPath string
-		if projectData.Preview != "" {
-			previewPath = fmt.Sprintf("wallpaper://%s", filepath.Join(basePath, folderName, projectData.Preview))
-		}
+// ReadWallpaperInfo is GetWallpaperInfo for callers that have already
+// initialized the configuration, such as loops over many wallpapers.
+func ReadWallpaperInfo(wallpaperID string) (*WallpaperProjectData, error) {
+	return readProjectData(filepath.Join(WallpaperPath(wallpaperID), "project.json"))
+}
 
-		var installDate int64
-		if info, err := entry.Info(); err == nil {
-			if stat, ok := info.Sys().(*syscall.Stat_t); ok {
-				installDate = stat.Ctim.Sec
-			} else {
-				installDate = info.ModTime().Unix()
-			}
-		}
+// SupportedType reports whether linux-wallpaperengine can render a wallpaper
+// type. Applications and presets only run inside Wallpaper Engine itself.
+func SupportedType(wallpaperType string) bool {
+	switch strings.ToLower(wallpaperType) {
+	case "scene", "video", "web":
+		return true
+	}
+	return false
+}
 
-		wallpapers[folderName] = WallpaperData{
-			ProjectData: &projectData,
-			PreviewPath: previewPath,
-			InstallDate: installDate,
-		}
+func readProjectData(projectJSONPath string) (*WallpaperProjectData, error) {
+	data, err := os.ReadFile(projectJSONPath)
+	if err != nil {
+		return nil, err
 	}
 
-	return wallpapers, nil
+	var projectData WallpaperProjectData
+	if err := json.Unmarshal(data, &projectData); err != nil {
+		return nil, err
+	}
+	return &projectData, nil
 }
 
 func GetWallpaperProjectData(folderName string) (map[string]interface{}, error) {
@@ -79,7 +71,7 @@ func GetWallpaperProjectData(folderName string) (map[string]interface{}, error)
 		return nil, err
 	}
 
-	projectJSONPath := filepath.Join(config.WorkshopPath, folderName, "project.json")
+	projectJSONPath := filepath.Join(config.GetWorkshopPath(), folderName, "project.json")
 	data, err := os.ReadFile(projectJSONPath)
 	if err != nil {
 		return nil, err
@@ -117,7 +109,7 @@ func GetWEConfigPath() (string, error) {
 		return "", err
 	}
 
-	workshopPath := config.WorkshopPath
+	workshopPath := config.GetWorkshopPath()
 	if workshopPath == "" {
 		return "", fmt.Errorf("wallpaper path not initialized")
 	}
@@ -0,0 +1,587 @@
+package wallpaper
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VideoInfo is what ProbeVideo learns from a container's headers.
+type VideoInfo struct {
+	Duration time.Duration `json:"duration"`
+	Width    int           `json:"width,omitempty"`
+	Height   int           `json:"height,omitempty"`
+}
+
+var errUnsupportedContainer = errors.New("unsupported video container")
+
+// ProbeVideo reads the duration and frame size of an MP4/MOV or WebM/Matroska
+// file from its headers, without decoding any media.
+func ProbeVideo(path string) (VideoInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return VideoInfo{}, err
+	}
+	defer file.Close()
+
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return VideoInfo{}, fmt.Errorf("failed to read video header: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return VideoInfo{}, err
+	}
+
+	switch {
+	case binary.BigEndian.Uint32(header[0:4]) == ebmlHeaderID:
+		return probeMatroska(file)
+	case isMP4Box(string(header[4:8])):
+		return probeMP4(file)
+	}
+	return VideoInfo{}, errUnsupportedContainer
+}
+
+var (
+	probeCache   = make(map[string]cachedProbe)
+	probeCacheMu sync.Mutex
+)
+
+type cachedProbe struct {
+	modTime time.Time
+	size    int64
+	info    VideoInfo
+}
+
+// probeVideoCached probes a video once per file version.
+func probeVideoCached(videoPath string) (VideoInfo, error) {
+	stat, err := os.Stat(videoPath)
+	if err != nil {
+		return VideoInfo{}, err
+	}
+
+	probeCacheMu.Lock()
+	cached, ok := probeCache[videoPath]
+	probeCacheMu.Unlock()
+	if ok && cached.modTime.Equal(stat.ModTime()) && cached.size == stat.Size() {
+		return cached.info, nil
+	}
+
+	info, err := ProbeVideo(videoPath)
+	if err != nil {
+		return VideoInfo{}, err
+	}
+
+	probeCacheMu.Lock()
+	probeCache[videoPath] = cachedProbe{modTime: stat.ModTime(), size: stat.Size(), info: info}
+	probeCacheMu.Unlock()
+	return info, nil
+}
+
+// GetWallpaperVideoDuration returns how long a Video wallpaper's file plays.
+// Other wallpaper types have no natural end and report zero. It is called on
+// every playlist change, so it relies on the paths detected at startup and
+// the catalog rather than scanning again.
+func GetWallpaperVideoDuration(wallpaperID string) (time.Duration, error) {
+	projectData, err := catalogProjectData(wallpaperID)
+	if err != nil {
+		return 0, err
+	}
+	if !strings.EqualFold(projectData.Type, "video") || projectData.File == "" {
+		return 0, nil
+	}
+
+	info, err := probeVideoCached(filepath.Join(WallpaperPath(wallpaperID), projectData.File))
+	if err != nil {
+		return 0, err
+	}
+	return info.Duration, nil
+}
+
+// --- MP4 / ISO base media ---
+
+func isMP4Box(boxType string) bool {
+	switch boxType {
+	case "ftyp", "moov", "mdat", "free", "skip", "wide", "pnot":
+		return true
+	}
+	return false
+}
+
+type mp4Box struct {
+	boxType    string
+	bodyOffset int64
+	bodySize   int64
+}
+
+// readMP4Box reads a box header at the reader's position. A size of -1 means
+// the box extends to the end of its parent.
+func readMP4Box(reader io.ReadSeeker) (mp4Box, error) {
+	offset, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return mp4Box{}, err
+	}
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return mp4Box{}, err
+	}
+
+	size := int64(binary.BigEndian.Uint32(header[0:4]))
+	box := mp4Box{boxType: string(header[4:8])}
+	headerSize := int64(8)
+
+	switch size {
+	case 0:
+		box.bodySize = -1
+	case 1:
+		large := make([]byte, 8)
+		if _, err := io.ReadFull(reader, large); err != nil {
+			return mp4Box{}, err
+		}
+		headerSize = 16
+		box.bodySize = int64(binary.BigEndian.Uint64(large)) - headerSize
+	default:
+		box.bodySize = size - headerSize
+	}
+
+	if box.bodySize < -1 {
+		return mp4Box{}, fmt.Errorf("invalid mp4 box size for %q", box.boxType)
+	}
+	box.bodyOffset = offset + headerSize
+	return box, nil
+}
+
+func findMP4Box(reader io.ReadSeeker, end int64, boxType string) (mp4Box, error) {
+	for {
+		position, err := reader.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return mp4Box{}, err
+		}
+		if end >= 0 && position >= end {
+			return mp4Box{}, fmt.Errorf("mp4 box %q not found", boxType)
+		}
+
+		box, err := readMP4Box(reader)
+		if err != nil {
+			return mp4Box{}, fmt.Errorf("mp4 box %q not found: %w", boxType, err)
+		}
+		if box.boxType == boxType {
+			return box, nil
+		}
+		if box.bodySize < 0 {
+			return mp4Box{}, fmt.Errorf("mp4 box %q not found", boxType)
+		}
+		if _, err := reader.Seek(box.bodyOffset+box.bodySize, io.SeekStart); err != nil {
+			return mp4Box{}, err
+		}
+	}
+}
+
+func boxEnd(box mp4Box) int64 {
+	if box.bodySize < 0 {
+		return -1
+	}
+	return box.bodyOffset + box.bodySize
+}
+
+func probeMP4(reader io.ReadSeeker) (VideoInfo, error) {
+	moov, err := findMP4Box(reader, -1, "moov")
+	if err != nil {
+		return VideoInfo{}, err
+	}
+
+	var info VideoInfo
+	foundHeader := false
+	end := boxEnd(moov)
+	for {
+		position, err := reader.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return VideoInfo{}, err
+		}
+		if end >= 0 && position >= end {
+			break
+		}
+
+		box, err := readMP4Box(reader)
+		if err != nil {
+			break
+		}
+		switch box.boxType {
+		case "mvhd":
+			if info.Duration, err = readMovieHeader(reader); err != nil {
+				return VideoInfo{}, err
+			}
+			foundHeader = true
+		case "trak":
+			if info.Width == 0 {
+				info.Width, info.Height = readTrackSize(reader, box)
+			}
+		}
+		if box.bodySize < 0 {
+			break
+		}
+		if _, err := reader.Seek(box.bodyOffset+box.bodySize, io.SeekStart); err != nil {
+			return VideoInfo{}, err
+		}
+	}
+
+	if !foundHeader {
+		return VideoInfo{}, fmt.Errorf("mp4 box %q not found", "mvhd")
+	}
+	return info, nil
+}
+
+func readMovieHeader(reader io.Reader) (time.Duration, error) {
+	versionAndFlags := make([]byte, 4)
+	if _, err := io.ReadFull(reader, versionAndFlags); err != nil {
+		return 0, err
+	}
+
+	var timescale uint32
+	var duration uint64
+	if versionAndFlags[0] == 1 {
+		body := make([]byte, 28)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			return 0, err
+		}
+		timescale = binary.BigEndian.Uint32(body[16:20])
+		duration = binary.BigEndian.Uint64(body[20:28])
+	} else {
+		body := make([]byte, 16)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			return 0, err
+		}
+		timescale = binary.BigEndian.Uint32(body[8:12])
+		duration = uint64(binary.BigEndian.Uint32(body[12:16]))
+	}
+
+	if timescale == 0 {
+		return 0, fmt.Errorf("mp4 movie header has no timescale")
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second)), nil
+}
+
+// readTrackSize returns the presentation size from a track header. Audio
+// tracks report zero.
+func readTrackSize(reader io.ReadSeeker, trak mp4Box) (int, int) {
+	tkhd, err := findMP4Box(reader, boxEnd(trak), "tkhd")
+	if err != nil {
+		return 0, 0
+	}
+
+	versionAndFlags := make([]byte, 4)
+	if _, err := io.ReadFull(reader, versionAndFlags); err != nil {
+		return 0, 0
+	}
+
+	// Width and height are 16.16 fixed point and close the box; version 1
+	// headers use 64-bit times, which moves them 12 bytes further.
+	offset := 72
+	if versionAndFlags[0] == 1 {
+		offset = 84
+	}
+	if tkhd.bodySize >= 0 && tkhd.bodySize < int64(len(versionAndFlags)+offset+8) {
+		return 0, 0
+	}
+	body := make([]byte, offset+8)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return 0, 0
+	}
+	width := binary.BigEndian.Uint32(body[offset:offset+4]) >> 16
+	height := binary.BigEndian.Uint32(body[offset+4:offset+8]) >> 16
+	return int(width), int(height)
+}
+
+// --- WebM / Matroska (EBML) ---
+
+const (
+	ebmlHeaderID         = 0x1A45DFA3
+	matroskaSegmentID    = 0x18538067
+	matroskaInfoID       = 0x1549A966
+	matroskaTimecodeID   = 0x2AD7B1
+	matroskaDurationID   = 0x4489
+	matroskaClusterID    = 0x1F43B675
+	matroskaTracksID     = 0x1654AE6B
+	matroskaTrackEntryID = 0xAE
+	matroskaVideoID      = 0xE0
+	matroskaPixelWidth   = 0xB0
+	matroskaPixelHeight  = 0xBA
+	ebmlUnknownSize      = -1
+	defaultTimecodeScale = 1000000
+)
+
+type ebmlElement struct {
+	id         uint32
+	dataOffset int64
+	dataSize   int64
+}
+
+func readEBMLVarInt(reader io.Reader, keepMarker bool) (uint64, int, error) {
+	first := make([]byte, 1)
+	if _, err := io.ReadFull(reader, first); err != nil {
+		return 0, 0, err
+	}
+
+	length := 1
+	for mask := byte(0x80); length <= 8 && first[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 {
+		return 0, 0, fmt.Errorf("invalid ebml variable-length integer")
+	}
+
+	value := uint64(first[0])
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+	allOnes := value == uint64(0xFF>>length)
+
+	rest := make([]byte, length-1)
+	if _, err := io.ReadFull(reader, rest); err != nil {
+		return 0, 0, err
+	}
+	for _, b := range rest {
+		value = value<<8 | uint64(b)
+		allOnes = allOnes && b == 0xFF
+	}
+
+	if !keepMarker && allOnes {
+		return math.MaxUint64, length, nil
+	}
+	return value, length, nil
+}
+
+func readEBMLElement(reader io.ReadSeeker) (ebmlElement, error) {
+	offset, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return ebmlElement{}, err
+	}
+
+	id, idLength, err := readEBMLVarInt(reader, true)
+	if err != nil {
+		return ebmlElement{}, err
+	}
+	size, sizeLength, err := readEBMLVarInt(reader, false)
+	if err != nil {
+		return ebmlElement{}, err
+	}
+
+	element := ebmlElement{
+		id:         uint32(id),
+		dataOffset: offset + int64(idLength+sizeLength),
+		dataSize:   int64(size),
+	}
+	if size == math.MaxUint64 {
+		element.dataSize = ebmlUnknownSize
+	}
+	return element, nil
+}
+
+func readEBMLData(reader io.Reader, element ebmlElement) ([]byte, error) {
+	if element.dataSize < 0 || element.dataSize > 8 {
+		return nil, fmt.Errorf("unexpected ebml element size %d", element.dataSize)
+	}
+	data := make([]byte, element.dataSize)
+	_, err := io.ReadFull(reader, data)
+	return data, err
+}
+
+func ebmlUint(data []byte) uint64 {
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+func ebmlFloat(data []byte) (float64, error) {
+	switch len(data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+	}
+	return 0, fmt.Errorf("invalid ebml float size %d", len(data))
+}
+
+func skipEBMLElement(reader io.Seeker, element ebmlElement) error {
+	_, err := reader.Seek(element.dataOffset+element.dataSize, io.SeekStart)
+	return err
+}
+
+func probeMatroska(reader io.ReadSeeker) (VideoInfo, error) {
+	header, err := readEBMLElement(reader)
+	if err != nil {
+		return VideoInfo{}, err
+	}
+	if err := skipEBMLElement(reader, header); err != nil {
+		return VideoInfo{}, err
+	}
+
+	segment, err := readEBMLElement(reader)
+	if err != nil {
+		return VideoInfo{}, err
+	}
+	if segment.id != matroskaSegmentID {
+		return VideoInfo{}, fmt.Errorf("matroska segment not found")
+	}
+
+	var info VideoInfo
+	foundInfo := false
+	for {
+		element, err := readEBMLElement(reader)
+		// Clusters hold the media itself; segment info and tracks precede them.
+		if err != nil || element.id == matroskaClusterID {
+			break
+		}
+		switch element.id {
+		case matroskaInfoID:
+			if info.Duration, err = probeMatroskaInfo(reader, element); err != nil {
+				return VideoInfo{}, err
+			}
+			foundInfo = true
+		case matroskaTracksID:
+			info.Width, info.Height = probeMatroskaTracks(reader, element)
+		}
+		if element.dataSize < 0 {
+			break
+		}
+		if err := skipEBMLElement(reader, element); err != nil {
+			return VideoInfo{}, err
+		}
+		if foundInfo && info.Width > 0 {
+			break
+		}
+	}
+
+	if !foundInfo {
+		return VideoInfo{}, fmt.Errorf("matroska segment info not found")
+	}
+	return info, nil
+}
+
+func probeMatroskaInfo(reader io.ReadSeeker, info ebmlElement) (time.Duration, error) {
+	timecodeScale := uint64(defaultTimecodeScale)
+	duration := -1.0
+
+	end := info.dataOffset + info.dataSize
+	for {
+		position, err := reader.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, err
+		}
+		if info.dataSize >= 0 && position >= end {
+			break
+		}
+
+		element, err := readEBMLElement(reader)
+		if err != nil {
+			return 0, err
+		}
+
+		switch element.id {
+		case matroskaTimecodeID:
+			data, err := readEBMLData(reader, element)
+			if err != nil {
+				return 0, err
+			}
+			timecodeScale = ebmlUint(data)
+		case matroskaDurationID:
+			data, err := readEBMLData(reader, element)
+			if err != nil {
+				return 0, err
+			}
+			if duration, err = ebmlFloat(data); err != nil {
+				return 0, err
+			}
+		default:
+			if element.dataSize < 0 {
+				return 0, fmt.Errorf("unexpected unknown-size matroska element")
+			}
+			if err := skipEBMLElement(reader, element); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if duration < 0 {
+		return 0, fmt.Errorf("matroska file has no duration")
+	}
+	return time.Duration(duration * float64(timecodeScale)), nil
+}
+
+// probeMatroskaTracks returns the pixel size of the first video track.
+func probeMatroskaTracks(reader io.ReadSeeker, tracks ebmlElement) (int, int) {
+	var width, height int
+	err := walkEBML(reader, tracks, func(element ebmlElement) (bool, error) {
+		switch element.id {
+		case matroskaTrackEntryID, matroskaVideoID:
+			return true, nil
+		case matroskaPixelWidth, matroskaPixelHeight:
+			data, err := readEBMLData(reader, element)
+			if err != nil {
+				return false, err
+			}
+			if element.id == matroskaPixelWidth && width == 0 {
+				width = int(ebmlUint(data))
+			} else if element.id == matroskaPixelHeight && height == 0 {
+				height = int(ebmlUint(data))
+			}
+		default:
+			if err := skipEBMLElement(reader, element); err != nil {
+				return false, err
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return 0, 0
+	}
+	return width, height
+}
+
+// walkEBML visits the children of a master element in order. The visitor
+// either consumes an element or returns true to descend into it.
+func walkEBML(reader io.ReadSeeker, parent ebmlElement, visit func(element ebmlElement) (bool, error)) error {
+	if parent.dataSize < 0 {
+		return fmt.Errorf("unexpected unknown-size matroska element")
+	}
+	if _, err := reader.Seek(parent.dataOffset, io.SeekStart); err != nil {
+		return err
+	}
+
+	end := parent.dataOffset + parent.dataSize
+	for {
+		position, err := reader.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		if position >= end {
+			return nil
+		}
+
+		element, err := readEBMLElement(reader)
+		if err != nil {
+			return err
+		}
+		descend, err := visit(element)
+		if err != nil {
+			return err
+		}
+		if descend {
+			if err := walkEBML(reader, element, visit); err != nil {
+				return err
+			}
+			if _, err := reader.Seek(element.dataOffset+element.dataSize, io.SeekStart); err != nil {
+				return err
+			}
+		}
+	}
+}
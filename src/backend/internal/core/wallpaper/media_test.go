@@ -0,0 +1,193 @@
+package wallpaper
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func mp4Box32(boxType string, body ...[]byte) []byte {
+	size := 8
+	for _, part := range body {
+		size += len(part)
+	}
+	box := binary.BigEndian.AppendUint32(nil, uint32(size))
+	box = append(box, boxType...)
+	for _, part := range body {
+		box = append(box, part...)
+	}
+	return box
+}
+
+// mp4Box64 writes a box with a 64-bit size field.
+func mp4Box64(boxType string, body []byte) []byte {
+	box := binary.BigEndian.AppendUint32(nil, 1)
+	box = append(box, boxType...)
+	box = binary.BigEndian.AppendUint64(box, uint64(16+len(body)))
+	return append(box, body...)
+}
+
+func movieHeader(version byte, timescale uint32, duration uint64) []byte {
+	body := []byte{version, 0, 0, 0}
+	if version == 1 {
+		body = append(body, make([]byte, 16)...)
+		body = binary.BigEndian.AppendUint32(body, timescale)
+		body = binary.BigEndian.AppendUint64(body, duration)
+	} else {
+		body = append(body, make([]byte, 8)...)
+		body = binary.BigEndian.AppendUint32(body, timescale)
+		body = binary.BigEndian.AppendUint32(body, uint32(duration))
+	}
+	// Rate, volume, matrix and the rest of the header.
+	body = append(body, make([]byte, 80)...)
+	return mp4Box32("mvhd", body)
+}
+
+func trackHeader(version byte, width, height uint32) []byte {
+	body := []byte{version, 0, 0, 0}
+	if version == 1 {
+		body = append(body, make([]byte, 84)...)
+	} else {
+		body = append(body, make([]byte, 72)...)
+	}
+	body = binary.BigEndian.AppendUint32(body, width<<16)
+	body = binary.BigEndian.AppendUint32(body, height<<16)
+	return mp4Box32("tkhd", body)
+}
+
+func ebml(id uint32, data ...[]byte) []byte {
+	var element []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if b := byte(id >> shift); b != 0 || len(element) > 0 {
+			element = append(element, b)
+		}
+	}
+	size := 0
+	for _, part := range data {
+		size += len(part)
+	}
+	// Eight-byte size: marker byte 0x01 followed by seven size bytes.
+	element = append(element, 0x01)
+	element = append(element, binary.BigEndian.AppendUint64(nil, uint64(size))[1:]...)
+	for _, part := range data {
+		element = append(element, part...)
+	}
+	return element
+}
+
+func ebmlFloat64(value float64) []byte {
+	return binary.BigEndian.AppendUint64(nil, math.Float64bits(value))
+}
+
+func ebmlFloat32(value float32) []byte {
+	return binary.BigEndian.AppendUint32(nil, math.Float32bits(value))
+}
+
+func ebmlUintBytes(value uint64) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(value))
+}
+
+func TestProbeVideo(t *testing.T) {
+	ftyp := mp4Box32("ftyp", []byte("isom\x00\x00\x02\x00isomiso2"))
+	mdat := mp4Box32("mdat", make([]byte, 32))
+	audioTrack := mp4Box32("trak", trackHeader(0, 0, 0))
+	ebmlHeader := ebml(ebmlHeaderID, ebml(0x4282, []byte("webm")))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    VideoInfo
+		wantErr error
+	}{
+		{
+			name: "mp4 version 0",
+			data: concat(ftyp, mp4Box32("moov", movieHeader(0, 1000, 12500), mp4Box32("trak", trackHeader(0, 1920, 1080))), mdat),
+			want: VideoInfo{Duration: 12500 * time.Millisecond, Width: 1920, Height: 1080},
+		},
+		{
+			name: "mp4 version 1 headers",
+			data: concat(ftyp, mp4Box32("moov", movieHeader(1, 600, 1800), mp4Box32("trak", trackHeader(1, 3840, 2160))), mdat),
+			want: VideoInfo{Duration: 3 * time.Second, Width: 3840, Height: 2160},
+		},
+		{
+			name: "mp4 audio track before video, moov after mdat",
+			data: concat(ftyp, mdat, mp4Box32("moov", movieHeader(0, 90000, 450000), audioTrack, mp4Box32("trak", mp4Box32("edts"), trackHeader(0, 1080, 1920)))),
+			want: VideoInfo{Duration: 5 * time.Second, Width: 1080, Height: 1920},
+		},
+		{
+			name: "mp4 64-bit box size",
+			data: concat(ftyp, mp4Box64("moov", concat(movieHeader(0, 1000, 2000), mp4Box32("trak", trackHeader(0, 640, 480))))),
+			want: VideoInfo{Duration: 2 * time.Second, Width: 640, Height: 480},
+		},
+		{
+			name: "mp4 truncated track header",
+			data: concat(ftyp, mp4Box32("moov", movieHeader(0, 1000, 1000), mp4Box32("trak", mp4Box32("tkhd", make([]byte, 40))))),
+			want: VideoInfo{Duration: time.Second},
+		},
+		{
+			name:    "mp4 without movie header",
+			data:    concat(ftyp, mp4Box32("moov", mp4Box32("trak", trackHeader(0, 640, 480)))),
+			wantErr: errors.New(`mp4 box "mvhd" not found`),
+		},
+		{
+			name: "webm",
+			data: concat(ebmlHeader, ebml(matroskaSegmentID,
+				ebml(matroskaInfoID, ebml(matroskaTimecodeID, ebmlUintBytes(1000000)), ebml(matroskaDurationID, ebmlFloat64(8000))),
+				ebml(matroskaTracksID,
+					ebml(matroskaTrackEntryID, ebml(0x83, []byte{2})),
+					ebml(matroskaTrackEntryID, ebml(matroskaVideoID, ebml(matroskaPixelWidth, ebmlUintBytes(2560)), ebml(matroskaPixelHeight, ebmlUintBytes(1440))))),
+				ebml(matroskaClusterID, make([]byte, 16)))),
+			want: VideoInfo{Duration: 8 * time.Second, Width: 2560, Height: 1440},
+		},
+		{
+			name: "webm float32 duration and default timecode scale",
+			data: concat(ebmlHeader, ebml(matroskaSegmentID, ebml(matroskaInfoID, ebml(matroskaDurationID, ebmlFloat32(1500))))),
+			want: VideoInfo{Duration: 1500 * time.Millisecond},
+		},
+		{
+			name:    "webm without duration",
+			data:    concat(ebmlHeader, ebml(matroskaSegmentID, ebml(matroskaInfoID, ebml(matroskaTimecodeID, ebmlUintBytes(1000000))))),
+			wantErr: errors.New("matroska file has no duration"),
+		},
+		{
+			name:    "unknown container",
+			data:    []byte("GIF89a\x01\x00\x01\x00\x00\x00"),
+			wantErr: errUnsupportedContainer,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "video")
+			if err := os.WriteFile(path, test.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ProbeVideo(path)
+			if test.wantErr != nil {
+				if err == nil || err.Error() != test.wantErr.Error() {
+					t.Fatalf("error = %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("info = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func concat(parts ...[]byte) []byte {
+	var joined []byte
+	for _, part := range parts {
+		joined = append(joined, part...)
+	}
+	return joined
+}
@@ -0,0 +1,102 @@
+package wallpaper
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Limits that keep a corrupt package from making us allocate absurd amounts.
+const (
+	maxPackageEntries    = 1 << 16
+	maxPackageStringSize = 4096
+)
+
+// packageEntry is one file of a Wallpaper Engine scene.pkg. The offset is
+// relative to the end of the header.
+type packageEntry struct {
+	offset uint32
+	length uint32
+}
+
+// readPackageFile extracts one file from a scene.pkg. The package starts with
+// a version string ("PKGV0001" and the like) and a file table of names,
+// offsets and lengths, all little-endian with length-prefixed strings,
+// followed by the file contents.
+func readPackageFile(packagePath, name string) ([]byte, error) {
+	file, err := os.Open(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(file)
+	version, err := readPackageString(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package header: %w", err)
+	}
+	if !strings.HasPrefix(version, "PKGV") {
+		return nil, fmt.Errorf("not a scene package: version %q", version)
+	}
+
+	var count uint32
+	if err := binary.Read(reader, binary.LittleEndian, &count); err != nil {
+		return nil, fmt.Errorf("failed to read package header: %w", err)
+	}
+	if count > maxPackageEntries {
+		return nil, fmt.Errorf("package lists %d files", count)
+	}
+
+	headerSize := int64(4 + len(version) + 4)
+	var found *packageEntry
+	for i := uint32(0); i < count; i++ {
+		entryName, err := readPackageString(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read package file table: %w", err)
+		}
+		var location [2]uint32
+		if err := binary.Read(reader, binary.LittleEndian, &location); err != nil {
+			return nil, fmt.Errorf("failed to read package file table: %w", err)
+		}
+		headerSize += int64(4 + len(entryName) + 8)
+		if found == nil && entryName == name {
+			found = &packageEntry{offset: location[0], length: location[1]}
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%s not found in package: %w", name, os.ErrNotExist)
+	}
+
+	start := headerSize + int64(found.offset)
+	if start+int64(found.length) > stat.Size() {
+		return nil, fmt.Errorf("%s extends past the end of the package", name)
+	}
+	data := make([]byte, found.length)
+	if _, err := file.ReadAt(data, start); err != nil {
+		return nil, fmt.Errorf("failed to read %s from package: %w", name, err)
+	}
+	return data, nil
+}
+
+func readPackageString(reader io.Reader) (string, error) {
+	var length uint32
+	if err := binary.Read(reader, binary.LittleEndian, &length); err != nil {
+		return "", err
+	}
+	if length > maxPackageStringSize {
+		return "", fmt.Errorf("string of %d bytes", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,539 @@
+package wallpaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+	"linux-wallpaperengine-gui/src/backend/internal/logger"
+)
+
+// conditionReferencePattern finds the properties a condition such as
+// "showclock.value == true && style.value != 2" reads.
+var conditionReferencePattern = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)\.value\b`)
+
+// rawProperty is a property as Wallpaper Engine writes it into project.json.
+// Numbers are sometimes written as strings, so they are decoded loosely.
+type rawProperty struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	Value     interface{}     `json:"value"`
+	Order     interface{}     `json:"order"`
+	Min       interface{}     `json:"min"`
+	Max       interface{}     `json:"max"`
+	Step      interface{}     `json:"step"`
+	Fraction  *bool           `json:"fraction"`
+	Options   json.RawMessage `json:"options"`
+	Condition string          `json:"condition"`
+}
+
+// GetWallpaperPropertySchema returns a wallpaper's user properties in display
+// order, typed and with their constraints.
+func GetWallpaperPropertySchema(wallpaperID string) ([]WallpaperProperty, error) {
+	if err := config.EnsureInitialized(); err != nil {
+		return nil, err
+	}
+	return readPropertySchema(wallpaperID)
+}
+
+// ReadWallpaperPropertySchema is GetWallpaperPropertySchema for callers that
+// have already initialized the configuration.
+func ReadWallpaperPropertySchema(wallpaperID string) ([]WallpaperProperty, error) {
+	return readPropertySchema(wallpaperID)
+}
+
+func readPropertySchema(wallpaperID string) ([]WallpaperProperty, error) {
+	data, err := os.ReadFile(filepath.Join(WallpaperPath(wallpaperID), "project.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var project struct {
+		General *struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"general"`
+		Properties  map[string]json.RawMessage `json:"properties"`
+		SchemeColor string                     `json:"schemecolor"`
+	}
+	if err := json.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse project.json: %w", err)
+	}
+
+	rawProperties := project.Properties
+	if project.General != nil {
+		rawProperties = project.General.Properties
+	}
+
+	properties := make([]WallpaperProperty, 0, len(rawProperties)+1)
+	for name, raw := range rawProperties {
+		properties = append(properties, parseProperty(name, raw))
+	}
+
+	if project.SchemeColor != "" {
+		if _, exists := rawProperties["schemecolor"]; !exists {
+			properties = append(properties, WallpaperProperty{
+				Name:        "schemecolor",
+				Type:        Color,
+				Description: "Theme Color",
+				Value:       project.SchemeColor,
+				Order:       -1,
+			})
+		}
+	}
+
+	sort.SliceStable(properties, func(i, j int) bool {
+		if properties[i].Order != properties[j].Order {
+			return properties[i].Order < properties[j].Order
+		}
+		return properties[i].Name < properties[j].Name
+	})
+	return properties, nil
+}
+
+// parseProperty converts one project.json property. Properties that cannot be
+// decoded are kept as Unknown so they still show up, just without checks.
+func parseProperty(name string, data json.RawMessage) WallpaperProperty {
+	var raw rawProperty
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return WallpaperProperty{Name: name, Type: Unknown, Description: name}
+	}
+
+	property := WallpaperProperty{
+		Name:        name,
+		Type:        normalizePropertyType(raw.Type),
+		Description: raw.Text,
+		Value:       raw.Value,
+		Min:         looseFloat(raw.Min),
+		Max:         looseFloat(raw.Max),
+		Step:        looseFloat(raw.Step),
+		Condition:   strings.TrimSpace(raw.Condition),
+	}
+	if property.Description == "" {
+		property.Description = name
+	}
+	if order := looseFloat(raw.Order); order != nil {
+		property.Order = int(*order)
+	}
+	if property.Type == Slider && raw.Fraction != nil && !*raw.Fraction {
+		property.Integer = true
+	}
+
+	if property.Type == ComboList {
+		property.Choices = parseOptions(raw.Options)
+		if len(property.Choices) > 0 {
+			property.Options = make(map[string]string, len(property.Choices))
+			for _, choice := range property.Choices {
+				property.Options[choice.Label] = choice.Value
+			}
+		}
+	}
+
+	if property.Condition != "" {
+		seen := make(map[string]bool)
+		for _, match := range conditionReferencePattern.FindAllStringSubmatch(property.Condition, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				property.DependsOn = append(property.DependsOn, match[1])
+			}
+		}
+	}
+	return property
+}
+
+func normalizePropertyType(propertyType string) PropertyType {
+	switch PropertyType(strings.ToLower(propertyType)) {
+	case Slider:
+		return Slider
+	case Bool, Boolean:
+		return Boolean
+	case Combo, ComboList:
+		return ComboList
+	case Color:
+		return Color
+	case Text:
+		return Text
+	case TextInput:
+		return TextInput
+	case Group:
+		return Group
+	}
+	return Unknown
+}
+
+// parseOptions accepts the list of {label, value} objects Wallpaper Engine
+// writes as well as a plain label to value object.
+func parseOptions(data json.RawMessage) []PropertyOption {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var list []struct {
+		Label string      `json:"label"`
+		Text  string      `json:"text"`
+		Value interface{} `json:"value"`
+	}
+	if err := json.Unmarshal(data, &list); err == nil {
+		options := make([]PropertyOption, 0, len(list))
+		for _, item := range list {
+			value := looseString(item.Value)
+			label := item.Label
+			if label == "" {
+				label = item.Text
+			}
+			if label == "" {
+				label = value
+			}
+			options = append(options, PropertyOption{Label: label, Value: value})
+		}
+		return options
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil
+	}
+	options := make([]PropertyOption, 0, len(object))
+	for label, value := range object {
+		options = append(options, PropertyOption{Label: label, Value: looseString(value)})
+	}
+	sort.Slice(options, func(i, j int) bool { return options[i].Label < options[j].Label })
+	return options
+}
+
+func looseFloat(value interface{}) *float64 {
+	switch typed := value.(type) {
+	case float64:
+		return &typed
+	case string:
+		if parsed, err := strconv.ParseFloat(strings.TrimSpace(typed), 64); err == nil {
+			return &parsed
+		}
+	}
+	return nil
+}
+
+func looseString(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case bool:
+		if typed {
+			return "1"
+		}
+		return "0"
+	}
+	return fmt.Sprint(value)
+}
+
+// ValidatePropertyValue checks a user value, as stored in the config, against
+// the property's type and constraints.
+func ValidatePropertyValue(property WallpaperProperty, value string) error {
+	value = strings.TrimSpace(value)
+	switch property.Type {
+	case Slider:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return fmt.Errorf("property '%s' needs a number, got '%s'", property.Name, value)
+		}
+		// Allow for rounding in values the UI computed from the step.
+		const epsilon = 1e-6
+		if property.Min != nil && number < *property.Min-epsilon {
+			return fmt.Errorf("property '%s' must be at least %g, got %g", property.Name, *property.Min, number)
+		}
+		if property.Max != nil && number > *property.Max+epsilon {
+			return fmt.Errorf("property '%s' must be at most %g, got %g", property.Name, *property.Max, number)
+		}
+		if property.Integer && math.Abs(number-math.Round(number)) > epsilon {
+			return fmt.Errorf("property '%s' needs a whole number, got %g", property.Name, number)
+		}
+	case Boolean:
+		if _, ok := parsePropertyBool(value); !ok {
+			return fmt.Errorf("property '%s' needs 1 or 0, got '%s'", property.Name, value)
+		}
+	case ComboList:
+		if len(property.Choices) == 0 {
+			return nil
+		}
+		for _, choice := range property.Choices {
+			if choice.Value == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("'%s' is not an option of property '%s'", value, property.Name)
+	case Color:
+		if _, err := parsePropertyColor(value); err != nil {
+			return fmt.Errorf("property '%s': %w", property.Name, err)
+		}
+	case Text, Group:
+		return fmt.Errorf("property '%s' is a label and cannot be set", property.Name)
+	}
+	return nil
+}
+
+func parsePropertyBool(value string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true":
+		return true, true
+	case "0", "false":
+		return false, true
+	}
+	return false, false
+}
+
+// parsePropertyColor reads a color as Wallpaper Engine's "r g b" floats in
+// 0–1, or as a "#rrggbb" or "#rgb" hex string, and returns its components in
+// 0–1. Byte components such as "255 128 0" are rejected: "1 1 1" would be
+// white as floats but nearly black as bytes.
+func parsePropertyColor(value string) ([3]float64, error) {
+	var color [3]float64
+	value = strings.TrimSpace(value)
+
+	if hex, ok := strings.CutPrefix(value, "#"); ok {
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 && len(hex) != 8 {
+			return color, fmt.Errorf("invalid hex color '%s'", value)
+		}
+		for i := range color {
+			component, err := strconv.ParseUint(hex[i*2:i*2+2], 16, 8)
+			if err != nil {
+				return color, fmt.Errorf("invalid hex color '%s'", value)
+			}
+			color[i] = float64(component) / 255
+		}
+		return color, nil
+	}
+
+	fields := strings.Fields(strings.ReplaceAll(value, ",", " "))
+	if len(fields) != 3 && len(fields) != 4 {
+		return color, fmt.Errorf("invalid color '%s', expected \"r g b\"", value)
+	}
+	for i, field := range fields {
+		component, err := strconv.ParseFloat(field, 64)
+		if err != nil || math.IsNaN(component) {
+			return color, fmt.Errorf("invalid color '%s', expected \"r g b\"", value)
+		}
+		if component < 0 || component > 1 {
+			return color, fmt.Errorf("invalid color '%s', components must be between 0 and 1", value)
+		}
+		if i < len(color) {
+			color[i] = component
+		}
+	}
+	return color, nil
+}
+
+// ValidateWallpaperProperties checks the per-wallpaper values that differ
+// from the current ones, so values saved before validation existed never
+// block unrelated config changes.
+func ValidateWallpaperProperties(current map[string]map[string]string, updated map[string]map[string]string) error {
+	changed := changedWallpapers(current, updated)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if err := config.EnsureInitialized(); err != nil {
+		return err
+	}
+	return propertyError(propertyProblems("", current, updated, changed))
+}
+
+// ValidateConfigProperties is ValidateWallpaperProperties for a whole config:
+// the per-wallpaper values and every screen's overrides.
+func ValidateConfigProperties(current config.AppConfig, updated config.AppConfig) error {
+	type propertyCheck struct {
+		label   string
+		current map[string]map[string]string
+		updated map[string]map[string]string
+		changed []string
+	}
+
+	checks := []propertyCheck{{current: current.WallpaperProperties, updated: updated.WallpaperProperties}}
+	for _, screen := range updated.Screens {
+		var previous map[string]map[string]string
+		for _, currentScreen := range current.Screens {
+			if currentScreen.Name == screen.Name {
+				previous = currentScreen.WallpaperProperties
+				break
+			}
+		}
+		checks = append(checks, propertyCheck{label: "screen " + screen.Name + ": ", current: previous, updated: screen.WallpaperProperties})
+	}
+
+	anyChanged := false
+	for i := range checks {
+		checks[i].changed = changedWallpapers(checks[i].current, checks[i].updated)
+		anyChanged = anyChanged || len(checks[i].changed) > 0
+	}
+	if !anyChanged {
+		return nil
+	}
+
+	if err := config.EnsureInitialized(); err != nil {
+		return err
+	}
+	var problems []string
+	for _, check := range checks {
+		problems = append(problems, propertyProblems(check.label, check.current, check.updated, check.changed)...)
+	}
+	return propertyError(problems)
+}
+
+func changedWallpapers(current map[string]map[string]string, updated map[string]map[string]string) []string {
+	var changed []string
+	for wallpaperID, values := range updated {
+		for name, value := range values {
+			if previous, ok := current[wallpaperID][name]; !ok || previous != value {
+				changed = append(changed, wallpaperID)
+				break
+			}
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// propertyProblems validates the changed values of the changed wallpapers.
+// The caller has initialized the configuration.
+func propertyProblems(label string, current map[string]map[string]string, updated map[string]map[string]string, changed []string) []string {
+	var problems []string
+	for _, wallpaperID := range changed {
+		schema, err := readPropertySchema(wallpaperID)
+		if err != nil {
+			// Wallpapers that are not installed cannot be checked.
+			continue
+		}
+		byName := make(map[string]WallpaperProperty, len(schema))
+		for _, property := range schema {
+			byName[property.Name] = property
+		}
+
+		names := make([]string, 0, len(updated[wallpaperID]))
+		for name := range updated[wallpaperID] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value := updated[wallpaperID][name]
+			if previous, ok := current[wallpaperID][name]; ok && previous == value {
+				continue
+			}
+			property, ok := byName[name]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s%s: unknown property '%s'", label, wallpaperID, name))
+				continue
+			}
+			if err := ValidatePropertyValue(property, value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s%s: %v", label, wallpaperID, err))
+			}
+		}
+	}
+	return problems
+}
+
+func propertyError(problems []string) error {
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid wallpaper properties: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// EncodePropertyValue converts a stored value into the text
+// linux-wallpaperengine expects: colors as "r g b" floats in 0–1, booleans as
+// 1 or 0, combo options by value rather than label, and plain numbers for
+// sliders.
+func EncodePropertyValue(property WallpaperProperty, value string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	// Older versions of the UI stored combo labels; map them to values.
+	if property.Type == ComboList {
+		for _, choice := range property.Choices {
+			if choice.Value != value && choice.Label == value {
+				value = choice.Value
+				break
+			}
+		}
+	}
+
+	if err := ValidatePropertyValue(property, value); err != nil {
+		return "", err
+	}
+
+	switch property.Type {
+	case Slider:
+		number, _ := strconv.ParseFloat(value, 64)
+		if property.Integer {
+			number = math.Round(number)
+		}
+		return formatPropertyNumber(number), nil
+	case Boolean:
+		if enabled, _ := parsePropertyBool(value); enabled {
+			return "1", nil
+		}
+		return "0", nil
+	case Color:
+		color, _ := parsePropertyColor(value)
+		return fmt.Sprintf("%s %s %s", formatPropertyNumber(color[0]), formatPropertyNumber(color[1]), formatPropertyNumber(color[2])), nil
+	}
+	return value, nil
+}
+
+// formatPropertyNumber drops float noise such as 0.30000000000000004 so the
+// same value always produces the same argument.
+func formatPropertyNumber(number float64) string {
+	number = math.Round(number*1e6) / 1e6
+	if number == 0 {
+		number = 0
+	}
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// propertyArguments builds the --set-property arguments for a wallpaper,
+// sorted by name so an unchanged configuration always yields the same
+// command line. Values the wallpaper's schema rejects are left out rather than
+// handed to the renderer.
+func propertyArguments(wallpaperID string, properties map[string]string) []string {
+	if len(properties) == 0 {
+		return nil
+	}
+
+	byName := make(map[string]WallpaperProperty)
+	if schema, err := readPropertySchema(wallpaperID); err == nil {
+		for _, property := range schema {
+			byName[property.Name] = property
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	arguments := make([]string, 0, len(names)*2)
+	for _, name := range names {
+		value := properties[name]
+		if property, ok := byName[name]; ok {
+			encoded, err := EncodePropertyValue(property, value)
+			if err != nil {
+				logger.Printf("Skipping property of wallpaper %s: %v", wallpaperID, err)
+				continue
+			}
+			value = encoded
+		}
+		arguments = append(arguments, "--set-property", fmt.Sprintf("%s=%s", name, value))
+	}
+	return arguments
+}
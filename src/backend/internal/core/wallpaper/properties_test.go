@@ -0,0 +1,74 @@
+package wallpaper
+
+import (
+	"strings"
+	"testing"
+)
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func TestEncodePropertyValue(t *testing.T) {
+	slider := WallpaperProperty{Name: "speed", Type: Slider, Min: float(0), Max: float(2)}
+	count := WallpaperProperty{Name: "count", Type: Slider, Min: float(1), Max: float(10), Integer: true}
+	toggle := WallpaperProperty{Name: "clock", Type: Boolean}
+	combo := WallpaperProperty{Name: "style", Type: ComboList, Choices: []PropertyOption{
+		{Label: "Digital", Value: "1"},
+		{Label: "Analog", Value: "2"},
+	}}
+	color := WallpaperProperty{Name: "schemecolor", Type: Color}
+	label := WallpaperProperty{Name: "heading", Type: Text}
+
+	tests := []struct {
+		name     string
+		property WallpaperProperty
+		value    string
+		want     string
+		wantErr  string
+	}{
+		{name: "slider", property: slider, value: " 1.5 ", want: "1.5"},
+		{name: "slider float noise", property: slider, value: "0.30000000000000004", want: "0.3"},
+		{name: "slider below min", property: slider, value: "-1", wantErr: "at least 0"},
+		{name: "slider above max", property: slider, value: "2.5", wantErr: "at most 2"},
+		{name: "slider not a number", property: slider, value: "fast", wantErr: "needs a number"},
+		{name: "slider NaN", property: slider, value: "NaN", wantErr: "needs a number"},
+		{name: "integer slider", property: count, value: "4.0000001", want: "4"},
+		{name: "integer slider fraction", property: count, value: "4.5", wantErr: "whole number"},
+		{name: "boolean true", property: toggle, value: "true", want: "1"},
+		{name: "boolean zero", property: toggle, value: "0", want: "0"},
+		{name: "boolean invalid", property: toggle, value: "yes", wantErr: "needs 1 or 0"},
+		{name: "combo value", property: combo, value: "2", want: "2"},
+		{name: "combo label", property: combo, value: "Digital", want: "1"},
+		{name: "combo unknown", property: combo, value: "3", wantErr: "not an option"},
+		{name: "color floats", property: color, value: "1 0.5 0", want: "1 0.5 0"},
+		{name: "color ones are white", property: color, value: "1 1 1", want: "1 1 1"},
+		{name: "color commas and alpha", property: color, value: "0.1, 0.2, 0.3, 1", want: "0.1 0.2 0.3"},
+		{name: "color hex", property: color, value: "#ff8000", want: "1 0.501961 0"},
+		{name: "color short hex", property: color, value: "#fff", want: "1 1 1"},
+		{name: "color bytes", property: color, value: "255 128 0", wantErr: "between 0 and 1"},
+		{name: "color negative", property: color, value: "-0.1 0 0", wantErr: "between 0 and 1"},
+		{name: "color alpha above one", property: color, value: "0 0 0 2", wantErr: "between 0 and 1"},
+		{name: "color too few components", property: color, value: "0 0", wantErr: `expected "r g b"`},
+		{name: "color bad hex", property: color, value: "#ggg", wantErr: "invalid hex color"},
+		{name: "label", property: label, value: "x", wantErr: "cannot be set"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := EncodePropertyValue(test.property, test.value)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("encoded = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package wallpaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"linux-wallpaperengine-gui/src/backend/internal/config"
+)
+
+// Resolution is a wallpaper's native size. The zero value means the wallpaper
+// adapts to any screen, or that its size is unknown.
+type Resolution struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+func (resolution Resolution) Known() bool {
+	return resolution.Width > 0 && resolution.Height > 0
+}
+
+func (resolution Resolution) AspectRatio() float64 {
+	if !resolution.Known() {
+		return 0
+	}
+	return float64(resolution.Width) / float64(resolution.Height)
+}
+
+var (
+	sceneSizeCache   = make(map[string]cachedSceneSize)
+	sceneSizeCacheMu sync.Mutex
+)
+
+type cachedSceneSize struct {
+	modTime    time.Time
+	size       int64
+	resolution Resolution
+}
+
+// GetWallpaperResolutions looks up the native resolution of several
+// wallpapers. Wallpapers whose size cannot be determined are left out.
+func GetWallpaperResolutions(wallpaperIDs []string) (map[string]Resolution, error) {
+	if err := config.EnsureInitialized(); err != nil {
+		return nil, err
+	}
+
+	resolutions := make(map[string]Resolution, len(wallpaperIDs))
+	for _, wallpaperID := range wallpaperIDs {
+		if resolution, err := wallpaperResolution(WallpaperPath(wallpaperID)); err == nil && resolution.Known() {
+			resolutions[wallpaperID] = resolution
+		}
+	}
+	return resolutions, nil
+}
+
+// wallpaperResolution reads the size from the video headers for Video
+// wallpapers, falling back to the preview image, and from the orthogonal
+// projection for scenes, whose scene.json is usually packed in scene.pkg. Scenes using the "auto" projection and web
+// wallpapers render at the screen's size and report zero.
+func wallpaperResolution(wallpaperPath string) (Resolution, error) {
+	projectData, err := readProjectData(filepath.Join(wallpaperPath, "project.json"))
+	if err != nil {
+		return Resolution{}, err
+	}
+
+	switch strings.ToLower(projectData.Type) {
+	case "video":
+		if projectData.File != "" {
+			if info, err := probeVideoCached(filepath.Join(wallpaperPath, projectData.File)); err == nil && info.Width > 0 && info.Height > 0 {
+				return Resolution{Width: info.Width, Height: info.Height}, nil
+			}
+		}
+		if projectData.Preview != "" {
+			return imageResolution(filepath.Join(wallpaperPath, projectData.Preview))
+		}
+	case "scene":
+		sceneFile := projectData.File
+		if sceneFile == "" {
+			sceneFile = "scene.json"
+		}
+		return sceneResolution(wallpaperPath, sceneFile)
+	}
+	return Resolution{}, nil
+}
+
+func imageResolution(path string) (Resolution, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Resolution{}, err
+	}
+	defer file.Close()
+
+	imageConfig, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return Resolution{}, fmt.Errorf("failed to read image size: %w", err)
+	}
+	return Resolution{Width: imageConfig.Width, Height: imageConfig.Height}, nil
+}
+
+func sceneResolution(wallpaperPath, sceneFile string) (Resolution, error) {
+	// Workshop scenes normally ship scene.json inside scene.pkg; a loose file
+	// next to project.json wins, as it does for the renderer.
+	source := filepath.Join(wallpaperPath, sceneFile)
+	stat, err := os.Stat(source)
+	packed := os.IsNotExist(err)
+	if packed {
+		source = filepath.Join(wallpaperPath, "scene.pkg")
+		stat, err = os.Stat(source)
+	}
+	if err != nil {
+		return Resolution{}, err
+	}
+
+	sceneSizeCacheMu.Lock()
+	cached, ok := sceneSizeCache[source]
+	sceneSizeCacheMu.Unlock()
+	if ok && cached.modTime.Equal(stat.ModTime()) && cached.size == stat.Size() {
+		return cached.resolution, nil
+	}
+
+	var data []byte
+	if packed {
+		data, err = readPackageFile(source, filepath.ToSlash(sceneFile))
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return Resolution{}, err
+	}
+
+	var scene struct {
+		General struct {
+			OrthogonalProjection *struct {
+				Auto   bool    `json:"auto"`
+				Width  float64 `json:"width"`
+				Height float64 `json:"height"`
+			} `json:"orthogonalprojection"`
+		} `json:"general"`
+	}
+	if err := json.Unmarshal(data, &scene); err != nil {
+		return Resolution{}, fmt.Errorf("failed to parse scene.json: %w", err)
+	}
+
+	var resolution Resolution
+	if projection := scene.General.OrthogonalProjection; projection != nil && !projection.Auto {
+		resolution = Resolution{Width: int(projection.Width), Height: int(projection.Height)}
+	}
+
+	sceneSizeCacheMu.Lock()
+	sceneSizeCache[source] = cachedSceneSize{modTime: stat.ModTime(), size: stat.Size(), resolution: resolution}
+	sceneSizeCacheMu.Unlock()
+	return resolution, nil
+}
@@ -0,0 +1,106 @@
+package wallpaper
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type packageFile struct {
+	name string
+	data string
+}
+
+// scenePackage builds a scene.pkg holding files in order.
+func scenePackage(files ...packageFile) []byte {
+	appendString := func(data []byte, value string) []byte {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(value)))
+		return append(data, value...)
+	}
+
+	data := appendString(nil, "PKGV0001")
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(files)))
+	offset := 0
+	for _, file := range files {
+		data = appendString(data, file.name)
+		data = binary.LittleEndian.AppendUint32(data, uint32(offset))
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(file.data)))
+		offset += len(file.data)
+	}
+	for _, file := range files {
+		data = append(data, file.data...)
+	}
+	return data
+}
+
+func TestSceneWallpaperResolution(t *testing.T) {
+	const ultrawide = `{"general": {"orthogonalprojection": {"width": 3440, "height": 1440}}}`
+	materials := packageFile{name: "materials/a.json", data: `{"passes": []}`}
+
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		want    Resolution
+		wantErr bool
+	}{
+		{
+			name:  "loose scene.json",
+			files: map[string][]byte{"scene.json": []byte(ultrawide)},
+			want:  Resolution{Width: 3440, Height: 1440},
+		},
+		{
+			name:  "scene.json packed after other files",
+			files: map[string][]byte{"scene.pkg": scenePackage(materials, packageFile{"scene.json", ultrawide})},
+			want:  Resolution{Width: 3440, Height: 1440},
+		},
+		{
+			name: "loose scene.json wins over the package",
+			files: map[string][]byte{
+				"scene.json": []byte(`{"general": {"orthogonalprojection": {"width": 1080, "height": 1920}}}`),
+				"scene.pkg":  scenePackage(packageFile{"scene.json", ultrawide}),
+			},
+			want: Resolution{Width: 1080, Height: 1920},
+		},
+		{
+			name:  "packed scene with auto projection",
+			files: map[string][]byte{"scene.pkg": scenePackage(packageFile{"scene.json", `{"general": {"orthogonalprojection": {"auto": true}}}`})},
+			want:  Resolution{},
+		},
+		{
+			name:    "package without scene.json",
+			files:   map[string][]byte{"scene.pkg": scenePackage(materials)},
+			wantErr: true,
+		},
+		{
+			name:    "truncated package",
+			files:   map[string][]byte{"scene.pkg": scenePackage(packageFile{"scene.json", ultrawide})[:40]},
+			wantErr: true,
+		},
+		{
+			name:    "not a package",
+			files:   map[string][]byte{"scene.pkg": []byte("\x04\x00\x00\x00ZIPSxxxx")},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			directory := t.TempDir()
+			test.files["project.json"] = []byte(`{"type": "scene", "file": "scene.json"}`)
+			for name, data := range test.files {
+				if err := os.WriteFile(filepath.Join(directory, name), data, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := wallpaperResolution(directory)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("error = %v, want error: %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("resolution = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -16,7 +16,6 @@ import (
 
 type Service struct {
 	processManager *process.Manager
-	wallpapers     map[string]WallpaperData
 }
 
 func NewService(processManager *process.Manager) *Service {
@@ -92,7 +91,7 @@ func (service *Service) ApplyWallpapers() error {
 				continue
 			}
 
-			execPath, args, cmdStr := service.buildWallpaperCommand(appConfig, screen.Name, wallpaperID)
+			execPath, args, cmdStr := service.buildWallpaperCommand(appConfig, screen, wallpaperID)
 			desiredWallpapers = append(desiredWallpapers, struct {
 				Screen  string
 				Exec    string
@@ -105,9 +104,7 @@ func (service *Service) ApplyWallpapers() error {
 	service.processManager.UpdateWallpapers(desiredWallpapers)
 
 	if appConfig.HookEnabled && appConfig.WallpaperChangeCommand != "" {
-		if service.wallpapers == nil {
-			service.wallpapers, _ = GetWallpapers()
-		}
+		wallpapers, _ := GetWallpapers()
 
 		if appConfig.SpanMode {
 			var screenNames []string
@@ -123,13 +120,13 @@ func (service *Service) ApplyWallpapers() error {
 			}
 
 			if wallpaperID != "" {
-				if wd, ok := service.wallpapers[wallpaperID]; ok && wd.ProjectData != nil && wd.ProjectData.Preview != "" {
+				if wd, ok := wallpapers[wallpaperID]; ok && wd.ProjectData != nil && wd.ProjectData.Preview != "" {
 					pd := wd.ProjectData
-					previewPath := filepath.Join(config.WorkshopPath, wallpaperID, pd.Preview)
+					previewPath := filepath.Join(config.GetWorkshopPath(), wallpaperID, pd.Preview)
 					var videoPath string
 					isVideo := "false"
 					if pd.Type == "Video" && pd.File != "" {
-						videoPath = filepath.Join(config.WorkshopPath, wallpaperID, pd.File)
+						videoPath = filepath.Join(config.GetWorkshopPath(), wallpaperID, pd.File)
 						isVideo = "true"
 					}
 
@@ -153,17 +150,17 @@ func (service *Service) ApplyWallpapers() error {
 					continue
 				}
 
-				wd, ok := service.wallpapers[wallpaperID]
+				wd, ok := wallpapers[wallpaperID]
 				if !ok || wd.ProjectData == nil || wd.ProjectData.Preview == "" {
 					continue
 				}
 				pd := wd.ProjectData
 
-				previewPath := filepath.Join(config.WorkshopPath, wallpaperID, pd.Preview)
+				previewPath := filepath.Join(config.GetWorkshopPath(), wallpaperID, pd.Preview)
 				var videoPath string
 				isVideo := "false"
 				if pd.Type == "Video" && pd.File != "" {
-					videoPath = filepath.Join(config.WorkshopPath, wallpaperID, pd.File)
+					videoPath = filepath.Join(config.GetWorkshopPath(), wallpaperID, pd.File)
 					isVideo = "true"
 				}
 
@@ -262,14 +259,71 @@ func (service *Service) getEffectiveWallpaperID(appConfig config.AppConfig, scre
 
 func (service *Service) buildSpanWallpaperCommand(appConfig config.AppConfig, screenNames []string, wallpaperID string) (string, []string, string) {
 	screenSpanArg := strings.Join(screenNames, ",")
-	return service.buildWallpaperCommandInternal(appConfig, []string{"--screen-span", screenSpanArg}, wallpaperID)
+	return service.buildWallpaperCommandInternal(appConfig, []string{"--screen-span", screenSpanArg}, wallpaperID, nil)
+}
+
+func (service *Service) buildWallpaperCommand(appConfig config.AppConfig, screen config.ScreenConfig, wallpaperID string) (string, []string, string) {
+	return service.buildWallpaperCommandInternal(appConfig, []string{"-r", screen.Name}, wallpaperID, &screen)
 }
 
-func (service *Service) buildWallpaperCommand(appConfig config.AppConfig, screenName string, wallpaperID string) (string, []string, string) {
-	return service.buildWallpaperCommandInternal(appConfig, []string{"-r", screenName}, wallpaperID)
+// applyRenderOverrides replaces the global renderer settings in appConfig with
+// the ones a screen overrides. Volume and mute stay independent: a screen that
+// only sets a volume is still silenced when the global config is.
+func applyRenderOverrides(appConfig *config.AppConfig, overrides config.RenderOverrides) {
+	if overrides.FPS != nil && *overrides.FPS > 0 {
+		appConfig.FPS = *overrides.FPS
+	}
+	if overrides.Volume != nil {
+		volume := *overrides.Volume
+		appConfig.Volume = &volume
+	}
+	if overrides.Silence != nil {
+		appConfig.Silence = *overrides.Silence
+	}
+	if overrides.FullscreenPauseIgnoreAppIds != nil {
+		appConfig.FullscreenPauseIgnoreAppIds = *overrides.FullscreenPauseIgnoreAppIds
+	}
+
+	for _, setting := range []struct {
+		override *bool
+		global   *bool
+	}{
+		{overrides.NoAutomute, &appConfig.NoAutomute},
+		{overrides.NoAudioProcessing, &appConfig.NoAudioProcessing},
+		{overrides.NoFullscreenPause, &appConfig.NoFullscreenPause},
+		{overrides.DisableParticles, &appConfig.DisableParticles},
+		{overrides.DisableMouse, &appConfig.DisableMouse},
+		{overrides.DisableParallax, &appConfig.DisableParallax},
+		{overrides.FullscreenPauseOnlyActive, &appConfig.FullscreenPauseOnlyActive},
+	} {
+		if setting.override != nil {
+			*setting.global = *setting.override
+		}
+	}
+
+	for _, setting := range []struct {
+		override *string
+		global   *string
+	}{
+		{overrides.Scaling, &appConfig.Scaling},
+		{overrides.Clamping, &appConfig.Clamping},
+		{overrides.Layer, &appConfig.Layer},
+	} {
+		if setting.override != nil {
+			*setting.global = *setting.override
+		}
+	}
 }
 
-func (service *Service) buildWallpaperCommandInternal(appConfig config.AppConfig, screenArgs []string, wallpaperID string) (string, []string, string) {
+// buildWallpaperCommandInternal builds the renderer command, with the screen's
+// render settings and property overrides on top of the global ones. The screen
+// is nil when the wallpaper is not shown on a single screen, as in span mode or
+// a preview.
+func (service *Service) buildWallpaperCommandInternal(appConfig config.AppConfig, screenArgs []string, wallpaperID string, screen *config.ScreenConfig) (string, []string, string) {
+	if screen != nil && screen.Render != nil {
+		applyRenderOverrides(&appConfig, *screen.Render)
+	}
+
 	fps := appConfig.FPS
 	if fps == 0 {
 		fps = 60
@@ -280,10 +334,7 @@ func (service *Service) buildWallpaperCommandInternal(appConfig config.AppConfig
 		executable = "linux-wallpaperengine"
 	}
 
-	wallpaperPath := wallpaperID
-	if config.WorkshopPath != "" {
-		wallpaperPath = filepath.Join(config.WorkshopPath, wallpaperID)
-	}
+	wallpaperPath := WallpaperPath(wallpaperID)
 
 	// Build arguments as a slice to avoid shell interpolation
 	arguments := append([]string{wallpaperPath}, screenArgs...)
@@ -340,24 +391,29 @@ func (service *Service) buildWallpaperCommandInternal(appConfig config.AppConfig
 
 	if appConfig.WallpaperEngineDir != "" {
 		arguments = append(arguments, "--assets-dir", appConfig.WallpaperEngineDir+"/assets")
-	} else if config.WallpaperEnginePath != "" {
-		arguments = append(arguments, "--assets-dir", config.WallpaperEnginePath+"/assets")
+	} else if wallpaperEnginePath := config.GetWallpaperEnginePath(); wallpaperEnginePath != "" {
+		arguments = append(arguments, "--assets-dir", wallpaperEnginePath+"/assets")
 	}
 
 	if appConfig.DumpStructure {
 		arguments = append(arguments, "--dump-structure")
 	}
 
-	// Properties
-	properties, ok := appConfig.WallpaperProperties[wallpaperID]
-	if !ok {
-		properties = appConfig.Properties
+	// Properties: global values, then the wallpaper's, then the screen's,
+	// each layer replacing only the keys it sets.
+	layers := []map[string]string{appConfig.Properties, appConfig.WallpaperProperties[wallpaperID]}
+	if screen != nil {
+		layers = append(layers, screen.WallpaperProperties[wallpaperID])
 	}
-
-	for key, value := range properties {
-		arguments = append(arguments, "--set-property", fmt.Sprintf("%s=%s", key, value))
+	properties := make(map[string]string)
+	for _, layer := range layers {
+		for name, value := range layer {
+			properties[name] = value
+		}
 	}
 
+	arguments = append(arguments, propertyArguments(wallpaperID, properties)...)
+
 	// Parse custom args using simple whitespace splitting. Note: quoted args not fully supported.
 	if appConfig.CustomArgsEnabled && appConfig.CustomArgs != "" {
 		customParts := strings.Fields(appConfig.CustomArgs)
@@ -373,19 +429,18 @@ func (service *Service) LoadWallpapers() (map[string]interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	service.wallpapers = wallpapers
 
 	appConfig, _ := config.GetConfig()
 	workshopPathValid := false
-	if config.WorkshopPath != "" {
-		if _, err := os.Stat(config.WorkshopPath); err == nil {
+	if workshopPath := config.GetWorkshopPath(); workshopPath != "" {
+		if _, err := os.Stat(workshopPath); err == nil {
 			workshopPathValid = true
 		}
 	}
 
 	wallpaperEnginePathValid := false
-	if config.WallpaperEnginePath != "" {
-		if _, err := os.Stat(config.WallpaperEnginePath); err == nil {
+	if wallpaperEnginePath := config.GetWallpaperEnginePath(); wallpaperEnginePath != "" {
+		if _, err := os.Stat(wallpaperEnginePath); err == nil {
 			wallpaperEnginePathValid = true
 		}
 	}
@@ -445,7 +500,7 @@ func (service *Service) StartPreview(wallpaperID string, geometry string) error
 	}
 
 	screenArgs := []string{"-w", geometry}
-	execPath, args, cmdStr := service.buildWallpaperCommandInternal(appConfig, screenArgs, wallpaperID)
+	execPath, args, cmdStr := service.buildWallpaperCommandInternal(appConfig, screenArgs, wallpaperID, nil)
 
 	logger.Printf("Starting wallpaper preview for %s... (%s)", wallpaperID, cmdStr)
 	service.processManager.UpdatePreview(execPath, args, cmdStr)
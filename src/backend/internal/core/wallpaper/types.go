@@ -75,5 +75,17 @@ type WallpaperProperty struct {
 	Min         *float64          `json:"min,omitempty"`
 	Max         *float64          `json:"max,omitempty"`
 	Step        *float64          `json:"step,omitempty"`
+	Integer     bool              `json:"integer,omitempty"`
 	Options     map[string]string `json:"options,omitempty"`
+	Choices     []PropertyOption  `json:"choices,omitempty"`
+	Order       int               `json:"order"`
+	Condition   string            `json:"condition,omitempty"`
+	DependsOn   []string          `json:"dependsOn,omitempty"`
+}
+
+// PropertyOption is one entry of a combo property, in the order the
+// wallpaper lists them.
+type PropertyOption struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
 }
@@ -4,87 +4,221 @@ import (
 	"linux-wallpaperengine-gui/src/backend/internal/config"
 	"linux-wallpaperengine-gui/src/backend/internal/logger"
 	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
 
+const (
+	EventWallpaperAdded   = "wallpaper-added"
+	EventWallpaperRemoved = "wallpaper-removed"
+	EventWallpaperUpdated = "wallpaper-updated"
+)
+
+const (
+	debounceDuration = 500 * time.Millisecond
+	// watchRetryInterval is how often the watcher checks whether the workshop
+	// folder appeared or moved, for example after Steam was set up or the
+	// library was changed in the settings.
+	watchRetryInterval = 30 * time.Second
+)
+
+// WallpaperEventHandler receives one event per added, removed or updated
+// wallpaper folder. The data is nil for removed wallpapers.
+type WallpaperEventHandler func(event string, folderName string, data *WallpaperData)
+
 var (
-	watcher *fsnotify.Watcher
+	watcher     *fsnotify.Watcher
+	watcherMu   sync.Mutex
+	watchedPath string
+	stopWatcher chan struct{}
 )
 
-func StartWallpaperWatcher(onChange func(path string, op string)) {
+// StartWallpaperWatcher watches the workshop folder and every wallpaper
+// folder in it, keeps the catalog current and reports each change.
+func StartWallpaperWatcher(onChange WallpaperEventHandler) {
+	watcherMu.Lock()
+	defer watcherMu.Unlock()
 	if watcher != nil {
 		return
 	}
 
-	err := config.EnsureInitialized()
+	w, err := fsnotify.NewWatcher()
 	if err != nil {
-		logger.Printf("Failed to initialize config for watcher: %v", err)
+		logger.Printf("Failed to create fs watcher: %v", err)
 		return
 	}
+	watcher = w
+	stopWatcher = make(chan struct{})
 
-	basePath := config.WorkshopPath
-	if _, err := os.Stat(basePath); os.IsNotExist(err) {
-		logger.Printf("Wallpaper path does not exist, watcher not started: %s", basePath)
-		return
+	go runWatcher(w, stopWatcher, onChange)
+}
+
+func runWatcher(w *fsnotify.Watcher, stop chan struct{}, onChange WallpaperEventHandler) {
+	retry := time.NewTicker(watchRetryInterval)
+	defer retry.Stop()
+
+	var timer *time.Timer
+	flush := func() {
+		changes, err := RefreshCatalog()
+		if err != nil {
+			logger.Printf("Failed to refresh wallpaper catalog: %v", err)
+			return
+		}
+		reportChanges(changes, onChange)
 	}
 
-	w, err := fsnotify.NewWatcher()
-	if err != nil {
-		logger.Printf("Failed to create fs watcher: %v", err)
+	updateWatchedPath(w, onChange)
+	for {
+		select {
+		case <-stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-retry.C:
+			updateWatchedPath(w, onChange)
+		case event, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			// New wallpaper folders are watched too, so edits inside them are
+			// seen from the start.
+			if event.Op&fsnotify.Create == fsnotify.Create && filepath.Dir(event.Name) == currentWatchedPath() {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := w.Add(event.Name); err != nil {
+						logger.Printf("Failed to watch wallpaper folder %s: %v", event.Name, err)
+					}
+				}
+			}
+
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(debounceDuration, flush)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			logger.Printf("Watcher error: %v", err)
+		}
+	}
+}
+
+func currentWatchedPath() string {
+	watcherMu.Lock()
+	defer watcherMu.Unlock()
+	return watchedPath
+}
+
+// updateWatchedPath starts watching the workshop folder once it exists and
+// moves the watches when the folder changes.
+func updateWatchedPath(w *fsnotify.Watcher, onChange WallpaperEventHandler) {
+	if err := config.EnsureInitialized(); err != nil {
+		logger.Printf("Failed to initialize config for watcher: %v", err)
 		return
 	}
-	watcher = w
+	basePath := config.GetWorkshopPath()
+	if basePath != "" && !isDir(basePath) {
+		basePath = ""
+	}
 
-	go func() {
-		var timer *time.Timer
-		const debounceDuration = 500 * time.Millisecond
+	watcherMu.Lock()
+	previous := watchedPath
+	watcherMu.Unlock()
+	if basePath == previous {
+		return
+	}
 
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					return
-				}
-				// We care about folder creation, removal, and renaming
-				if event.Op&fsnotify.Create == fsnotify.Create ||
-					event.Op&fsnotify.Remove == fsnotify.Remove ||
-					event.Op&fsnotify.Rename == fsnotify.Rename {
+	for _, path := range w.WatchList() {
+		_ = w.Remove(path)
+	}
+	watcherMu.Lock()
+	watchedPath = ""
+	watcherMu.Unlock()
+	setCatalogWatched(false)
 
-					if timer != nil {
-						timer.Stop()
-					}
+	if basePath == "" {
+		logger.Printf("Wallpaper folder is not available, waiting for it to appear")
+		return
+	}
 
-					timer = time.AfterFunc(debounceDuration, func() {
-						logger.Printf("Wallpaper folder changed (debounced): %s (%s)", event.Name, event.Op)
-						if onChange != nil {
-							onChange(event.Name, event.Op.String())
-						}
-					})
-				}
-			case err, ok := <-watcher.Errors:
-				if !ok {
-					return
-				}
-				logger.Printf("Watcher error: %v", err)
+	if err := w.Add(basePath); err != nil {
+		logger.Printf("Failed to add path to watcher: %v", err)
+		return
+	}
+	if entries, err := os.ReadDir(basePath); err == nil {
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			if err := w.Add(filepath.Join(basePath, entry.Name())); err != nil {
+				logger.Printf("Failed to watch wallpaper folder %s: %v", entry.Name(), err)
 			}
 		}
-	}()
+	}
+
+	watcherMu.Lock()
+	watchedPath = basePath
+	watcherMu.Unlock()
+	logger.Printf("Started watching wallpaper directory: %s", basePath)
 
-	err = watcher.Add(basePath)
+	// Whatever changed while nothing was watched, including a whole new
+	// folder, is reported like any other change.
+	changes, err := RefreshCatalog()
 	if err != nil {
-		logger.Printf("Failed to add path to watcher: %v", err)
+		logger.Printf("Failed to refresh wallpaper catalog: %v", err)
 	} else {
-		logger.Printf("Started watching wallpaper directory: %s", basePath)
+		reportChanges(changes, onChange)
+	}
+	setCatalogWatched(true)
+}
+
+func reportChanges(changes CatalogChanges, onChange WallpaperEventHandler) {
+	if onChange == nil || changes.Empty() {
+		return
+	}
+
+	wallpapers, err := GetWallpapers()
+	if err != nil {
+		logger.Printf("Failed to get wallpapers: %v", err)
+		return
+	}
+	report := func(event string, folderNames []string) {
+		for _, folderName := range folderNames {
+			if data, ok := wallpapers[folderName]; ok {
+				onChange(event, folderName, &data)
+			}
+		}
 	}
+
+	report(EventWallpaperAdded, changes.Added)
+	for _, folderName := range changes.Removed {
+		onChange(EventWallpaperRemoved, folderName, nil)
+	}
+	report(EventWallpaperUpdated, changes.Updated)
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
 }
 
 func StopWallpaperWatcher() {
+	watcherMu.Lock()
+	defer watcherMu.Unlock()
 	if watcher != nil {
+		close(stopWatcher)
+		setCatalogWatched(false)
 		if err := watcher.Close(); err != nil {
 			logger.Printf("Error closing wallpaper watcher: %v", err)
 		}
 		watcher = nil
+		watchedPath = ""
 	}
 }
@@ -2,28 +2,86 @@ package display
 
 import (
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// ScreenInfo describes a connected output. Width and Height are the size on
+// the desktop, so they already account for rotation; outputs without an
+// active mode report zero.
+type ScreenInfo struct {
+	Name     string `json:"name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	X        int    `json:"x"`
+	Y        int    `json:"y"`
+	Rotation string `json:"rotation"`
+	Primary  bool   `json:"primary"`
+}
+
+var geometryPattern = regexp.MustCompile(`^(\d+)x(\d+)\+(\d+)\+(\d+)$`)
+
 func GetScreens() ([]string, error) {
-	out, err := exec.Command("xrandr", "--query").Output()
+	infos, err := GetScreenInfo()
 	if err != nil {
 		return nil, err
 	}
 
-	lines := strings.Split(string(out), "\n")
 	var screens []string
-	for _, line := range lines {
-		if strings.Contains(line, " connected") {
-			parts := strings.Split(line, " ")
-			if len(parts) > 0 {
-				screens = append(screens, parts[0])
+	for _, info := range infos {
+		screens = append(screens, info.Name)
+	}
+	return screens, nil
+}
+
+func GetScreenInfo() ([]ScreenInfo, error) {
+	out, err := exec.Command("xrandr", "--query").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseScreenInfo(string(out)), nil
+}
+
+// parseScreenInfo reads xrandr output lines such as
+// "DP-1 connected primary 1080x1920+2560+0 left (normal left inverted right x axis y axis) 527mm x 296mm".
+func parseScreenInfo(output string) []ScreenInfo {
+	var screens []ScreenInfo
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.Contains(line, " connected") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		info := ScreenInfo{Name: fields[0], Rotation: "normal"}
+		for i, field := range fields[1:] {
+			if strings.HasPrefix(field, "(") {
+				break
+			}
+			if field == "primary" {
+				info.Primary = true
+				continue
+			}
+			if matches := geometryPattern.FindStringSubmatch(field); matches != nil {
+				info.Width, _ = strconv.Atoi(matches[1])
+				info.Height, _ = strconv.Atoi(matches[2])
+				info.X, _ = strconv.Atoi(matches[3])
+				info.Y, _ = strconv.Atoi(matches[4])
+				if next := i + 2; next < len(fields) {
+					switch fields[next] {
+					case "normal", "left", "right", "inverted":
+						info.Rotation = fields[next]
+					}
+				}
 			}
 		}
+		screens = append(screens, info)
 	}
-
-	return screens, nil
+	return screens
 }
 
 func StartWatcher(callback func()) {
@@ -30,6 +30,8 @@ func StartDetector(callback func(bool)) {
 
 	go func() {
 		lastStatus := false
+		// The first check always reports, so callers learn the initial state.
+		firstCheck := true
 		for detectorRunning {
 			conf, err := config.GetConfig()
 			if err != nil {
@@ -39,8 +41,9 @@ func StartDetector(callback func(bool)) {
 
 			// If NoFullscreenPause is enabled, we don't need to detect
 			if conf.NoFullscreenPause {
-				if isFullscreenDetected {
+				if isFullscreenDetected || firstCheck {
 					isFullscreenDetected = false
+					firstCheck = false
 					if statusChangeCallback != nil {
 						statusChangeCallback(false)
 					}
@@ -52,9 +55,11 @@ func StartDetector(callback func(bool)) {
 			// Check for fullscreen window
 			currentStatus := checkFullscreenWindow()
 
-			if currentStatus != lastStatus {
+			if currentStatus != lastStatus || firstCheck {
+				changed := currentStatus != lastStatus
 				isFullscreenDetected = currentStatus
 				lastStatus = currentStatus
+				firstCheck = false
 
 				if statusChangeCallback != nil {
 					statusChangeCallback(currentStatus)
@@ -62,7 +67,7 @@ func StartDetector(callback func(bool)) {
 
 				if currentStatus {
 					logger.Printf("Fullscreen window detected - pausing playlist")
-				} else {
+				} else if changed {
 					logger.Printf("Fullscreen window closed - resuming playlist")
 				}
 			}
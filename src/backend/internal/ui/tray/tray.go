@@ -11,16 +11,20 @@ import (
 )
 
 var (
-	onShow             func()
-	onClose            func()
-	onRestartWallpaper func()
-	onQuit             func()
+	onShow              func()
+	onClose             func()
+	onRestartWallpaper  func()
+	onNextWallpaper     func()
+	onPreviousWallpaper func()
+	onQuit              func()
 )
 
-func RegisterCallbacks(show func(), close func(), restart func(), quit func()) {
+func RegisterCallbacks(show func(), close func(), restart func(), next func(), previous func(), quit func()) {
 	onShow = show
 	onClose = close
 	onRestartWallpaper = restart
+	onNextWallpaper = next
+	onPreviousWallpaper = previous
 	onQuit = quit
 }
 
@@ -72,6 +76,8 @@ func onReady() {
 	mShow := systray.AddMenuItem("Show", "Open the GUI")
 	mClose := systray.AddMenuItem("Hide", "Hide the GUI to system tray")
 	mRestart := systray.AddMenuItem("Restart Wallpaper", "Restart the current wallpapers")
+	mNext := systray.AddMenuItem("Next Wallpaper", "Skip to the next wallpaper in running playlists")
+	mPrevious := systray.AddMenuItem("Previous Wallpaper", "Go back to the previous wallpaper in running playlists")
 	systray.AddSeparator()
 	mQuit := systray.AddMenuItem("Quit", "Exit completely")
 
@@ -90,6 +96,14 @@ func onReady() {
 				if onRestartWallpaper != nil {
 					onRestartWallpaper()
 				}
+			case <-mNext.ClickedCh:
+				if onNextWallpaper != nil {
+					onNextWallpaper()
+				}
+			case <-mPrevious.ClickedCh:
+				if onPreviousWallpaper != nil {
+					onPreviousWallpaper()
+				}
 			case <-mQuit.ClickedCh:
 				if onQuit != nil {
 					onQuit()